@@ -0,0 +1,294 @@
+package analyzer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 读入压缩包时的大小限制，防止损坏或恶意构造的压缩包耗尽内存
+const (
+	maxArchiveFileSize  = 64 << 20 // 单个文件解压后的最大字节数
+	maxArchiveTotalSize = 1 << 30  // 压缩包本身以及读入内存的文件解压后的总字节数上限
+)
+
+// IsArchive 判断路径是否为可以直接分析的源代码压缩包: .zip、.tar、.tar.gz 或 .tgz
+func IsArchive(filePath string) bool {
+	name := strings.ToLower(filePath)
+	for _, ext := range []string{".zip", ".tar", ".tar.gz", ".tgz"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// OpenArchive 把源代码压缩包读入内存，返回其中的文件组成的 fs.FS，可以传给 AnalyzeFS
+// 压缩包中的符号链接和其他特殊文件被忽略；单个文件超过 64 MiB 或总大小超过 1 GiB 时返回错误
+func OpenArchive(filePath string) (fs.FS, error) {
+	return openArchive(filePath, nil)
+}
+
+// AnalyzeArchive 直接分析源代码压缩包中的整个项目，不需要解压
+// 与 OpenArchive 不同，tar 包中只读入可能是C++代码的文件和 .gitignore，其余行为与 AnalyzeFS 分析整个压缩包相同
+func (a *CppAnalyzer) AnalyzeArchive(filePath string) ([]*CppClass, error) {
+	return a.AnalyzeArchiveContext(context.Background(), filePath)
+}
+
+// AnalyzeArchiveContext 与 AnalyzeArchive 相同，ctx 被取消时停止分析并返回 ctx.Err()
+func (a *CppAnalyzer) AnalyzeArchiveContext(ctx context.Context, filePath string) ([]*CppClass, error) {
+	fsys, err := openArchive(filePath, a.keepArchiveFile)
+	if err != nil {
+		return nil, err
+	}
+	return a.AnalyzeFSContext(ctx, fsys, ".")
+}
+
+// keepArchiveFile 判断压缩包中的文件是否需要读入: 被 --exclude 排除的文件不会被分析；
+// 扩展名可以识别的C++文件、没有扩展名需要按内容判断的文件和 .gitignore 需要读入
+func (a *CppAnalyzer) keepArchiveFile(name string) bool {
+	filter := &fileFilter{root: ".", excludes: a.excludeRules}
+	if filter.skips(name) {
+		return false
+	}
+	return path.Base(name) == ".gitignore" || path.Ext(name) == "" || a.extensionKind(name) != ""
+}
+
+// openArchive 打开源代码压缩包，keep 不为 nil 时 tar 包中只读入 keep 返回 true 的文件
+// zip 包按需解压，不需要筛选
+func openArchive(filePath string, keep func(name string) bool) (fs.FS, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("无法打开压缩包 %s: %v", filePath, err)
+	}
+	defer file.Close()
+	name := strings.ToLower(filePath)
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		info, err := file.Stat()
+		if err != nil {
+			return nil, fmt.Errorf("无法读取压缩包 %s: %v", filePath, err)
+		}
+		if info.Size() > maxArchiveTotalSize {
+			return nil, fmt.Errorf("压缩包 %s 超过 %d 字节", filePath, int64(maxArchiveTotalSize))
+		}
+		content, err := io.ReadAll(file)
+		if err != nil {
+			return nil, fmt.Errorf("无法读取压缩包 %s: %v", filePath, err)
+		}
+		fsys, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+		if err != nil {
+			return nil, fmt.Errorf("无法读取压缩包 %s: %v", filePath, err)
+		}
+		return fsys, nil
+	case strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz"):
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("无法读取压缩包 %s: %v", filePath, err)
+		}
+		defer gz.Close()
+		return readTar(filePath, gz, keep)
+	case strings.HasSuffix(name, ".tar"):
+		return readTar(filePath, file, keep)
+	}
+	return nil, fmt.Errorf("不支持的压缩包格式: %s (可选: .zip, .tar, .tar.gz, .tgz)", filePath)
+}
+
+// readTar 读取 tar 包中的普通文件，keep 不为 nil 时跳过 keep 返回 false 的文件
+// 读入的文件超过 maxArchiveFileSize 或总大小超过 maxArchiveTotalSize 时返回错误
+func readTar(filePath string, r io.Reader, keep func(name string) bool) (fs.FS, error) {
+	fsys := &archiveFS{
+		files: map[string]*archiveEntry{".": {name: ".", mode: fs.ModeDir | 0755}},
+		dirs:  map[string][]fs.DirEntry{".": nil},
+	}
+	tr := tar.NewReader(r)
+	var total int64
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("无法读取压缩包 %s: %v", filePath, err)
+		}
+		name := path.Clean(strings.TrimPrefix(header.Name, "/"))
+		if name == "." || !fs.ValidPath(name) {
+			continue
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			fsys.addDir(name, header.ModTime)
+		case tar.TypeReg:
+			if keep != nil && !keep(name) {
+				continue
+			}
+			if header.Size > maxArchiveFileSize {
+				return nil, fmt.Errorf("压缩包 %s 中的 %s 超过 %d 字节", filePath, name, int64(maxArchiveFileSize))
+			}
+			if total += header.Size; total > maxArchiveTotalSize {
+				return nil, fmt.Errorf("压缩包 %s 中的文件总大小超过 %d 字节", filePath, int64(maxArchiveTotalSize))
+			}
+			// 头部记录的大小不可信，最多读取 header.Size 字节
+			data, err := io.ReadAll(io.LimitReader(tr, header.Size))
+			if err != nil {
+				return nil, fmt.Errorf("无法读取压缩包 %s 中的 %s: %v", filePath, name, err)
+			}
+			fsys.addFile(&archiveEntry{name: path.Base(name), data: data, mode: 0644, modTime: header.ModTime}, name)
+		}
+	}
+	for _, entries := range fsys.dirs {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	}
+	return fsys, nil
+}
+
+// archiveFS 读入内存的压缩包，实现 fs.FS 和 fs.ReadDirFS
+type archiveFS struct {
+	files map[string]*archiveEntry // 文件和目录，键为完整路径，根目录为 "."
+	dirs  map[string][]fs.DirEntry // 各目录中的条目
+}
+
+// addDir 登记目录及其上级目录，同名的文件被目录取代
+func (fsys *archiveFS) addDir(name string, modTime time.Time) {
+	if _, exists := fsys.dirs[name]; exists || name == "." {
+		return
+	}
+	fsys.remove(name)
+	entry := &archiveEntry{name: path.Base(name), mode: fs.ModeDir | 0755, modTime: modTime}
+	fsys.files[name] = entry
+	fsys.dirs[name] = nil
+	parent := path.Dir(name)
+	fsys.addDir(parent, modTime)
+	fsys.dirs[parent] = append(fsys.dirs[parent], entry)
+}
+
+// addFile 登记文件，同名的文件或目录以后出现的为准，被取代的目录连同其中的内容一起删除
+func (fsys *archiveFS) addFile(entry *archiveEntry, name string) {
+	if old, exists := fsys.files[name]; exists && !old.IsDir() {
+		*old = *entry
+		return
+	}
+	fsys.remove(name)
+	fsys.files[name] = entry
+	parent := path.Dir(name)
+	fsys.addDir(parent, entry.modTime)
+	fsys.dirs[parent] = append(fsys.dirs[parent], entry)
+}
+
+// remove 删除文件或目录及其中的内容，并从上级目录的条目中去掉
+func (fsys *archiveFS) remove(name string) {
+	old, exists := fsys.files[name]
+	if !exists {
+		return
+	}
+	if old.IsDir() {
+		for _, child := range fsys.dirs[name] {
+			fsys.remove(path.Join(name, child.Name()))
+		}
+		delete(fsys.dirs, name)
+	}
+	delete(fsys.files, name)
+	parent := path.Dir(name)
+	siblings := fsys.dirs[parent]
+	for i, sibling := range siblings {
+		if sibling == old {
+			fsys.dirs[parent] = append(siblings[:i:i], siblings[i+1:]...)
+			break
+		}
+	}
+}
+
+// Open 打开文件或目录
+func (fsys *archiveFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	entry, ok := fsys.files[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if entry.IsDir() {
+		return &archiveDir{entry: entry, entries: fsys.dirs[name]}, nil
+	}
+	return &archiveFile{entry: entry, reader: bytes.NewReader(entry.data)}, nil
+}
+
+// ReadDir 返回目录中按文件名排序的条目
+func (fsys *archiveFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	entries, ok := fsys.dirs[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]fs.DirEntry(nil), entries...), nil
+}
+
+// archiveEntry 压缩包中的一个文件或目录，同时实现 fs.FileInfo 和 fs.DirEntry
+type archiveEntry struct {
+	name    string
+	data    []byte
+	mode    fs.FileMode
+	modTime time.Time
+}
+
+func (e *archiveEntry) Name() string               { return e.name }
+func (e *archiveEntry) Size() int64                { return int64(len(e.data)) }
+func (e *archiveEntry) Mode() fs.FileMode          { return e.mode }
+func (e *archiveEntry) ModTime() time.Time         { return e.modTime }
+func (e *archiveEntry) IsDir() bool                { return e.mode.IsDir() }
+func (e *archiveEntry) Sys() any                   { return nil }
+func (e *archiveEntry) Type() fs.FileMode          { return e.mode.Type() }
+func (e *archiveEntry) Info() (fs.FileInfo, error) { return e, nil }
+
+// archiveFile 打开的文件
+type archiveFile struct {
+	entry  *archiveEntry
+	reader *bytes.Reader
+}
+
+func (f *archiveFile) Stat() (fs.FileInfo, error) { return f.entry, nil }
+func (f *archiveFile) Read(b []byte) (int, error) { return f.reader.Read(b) }
+func (f *archiveFile) Close() error               { return nil }
+
+// archiveDir 打开的目录
+type archiveDir struct {
+	entry   *archiveEntry
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *archiveDir) Stat() (fs.FileInfo, error) { return d.entry, nil }
+func (d *archiveDir) Close() error               { return nil }
+
+func (d *archiveDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.entry.name, Err: fs.ErrInvalid}
+}
+
+// ReadDir 实现 fs.ReadDirFile，n <= 0 时返回剩余的全部条目
+func (d *archiveDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return append([]fs.DirEntry(nil), rest...), nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.offset += n
+	return append([]fs.DirEntry(nil), rest[:n]...), nil
+}
@@ -0,0 +1,249 @@
+package analyzer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Version 分析器版本，参与缓存的键；解析逻辑或解析结果的结构变化时需要递增，使旧的缓存失效
+const Version = "1.1.0"
+
+// DefaultCacheDir 默认的缓存目录
+const DefaultCacheDir = ".easycpp-cache"
+
+// DefaultCacheLimit 缓存目录中默认最多保留的条目数，每个文件占用预扫描和解析两个条目
+const DefaultCacheLimit = 20000
+
+// CacheStats 最近一次分析中缓存的命中情况
+type CacheStats struct {
+	Hits   int // 直接使用缓存结果的文件数
+	Misses int // 重新解析的文件数
+}
+
+// cacheStats 分析过程中并发更新的缓存计数和本次用到的缓存条目
+type cacheStats struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+
+	mu   sync.Mutex
+	used map[string]bool // 本次分析读取或写入的缓存键
+}
+
+// use 记录本次分析用到了缓存键 key
+func (c *cacheStats) use(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.used == nil {
+		c.used = make(map[string]bool)
+	}
+	c.used[key] = true
+}
+
+// isUsed 返回本次分析是否用到了缓存键 key
+func (c *cacheStats) isUsed(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.used[key]
+}
+
+// cacheEntry 缓存文件中保存的单个文件的解析结果，即尚未做跨文件解析的 parseResult
+type cacheEntry struct {
+	Classes          []*CppClass
+	Includes         []Include
+	Definitions      []MethodDefinition
+	Diagnostics      []Diagnostic
+	InlineNamespaces []string
+}
+
+// scanCacheEntry 缓存文件中保存的单个文件的预扫描结果，即 scanResult
+type scanCacheEntry struct {
+	Includes []Include
+	Macros   map[string]*Macro
+}
+
+// SetCacheDir 设置缓存解析结果的目录，为空时不使用缓存(默认)
+// 每个文件的预扫描和解析结果以文件内容、分析器版本和生效的宏为键保存，这些都没有变化时不再重新预处理和解析；
+// 跨文件的基类解析、分类和定义关联每次都重新进行
+func (a *CppAnalyzer) SetCacheDir(dir string) {
+	a.cacheDir = dir
+}
+
+// SetCacheLimit 设置缓存目录中最多保留的条目数，默认为 DefaultCacheLimit
+// 每次分析结束后，超出上限时删除本次没有用到的条目中最久未用的；n <= 0 时只保留本次分析用到的条目
+func (a *CppAnalyzer) SetCacheLimit(n int) {
+	a.cacheLimit = n
+}
+
+// CacheStats 返回最近一次分析中缓存的命中和未命中的文件数
+func (a *CppAnalyzer) CacheStats() CacheStats {
+	return CacheStats{Hits: int(a.cache.hits.Load()), Misses: int(a.cache.misses.Load())}
+}
+
+// cacheKey 计算文件内容在给定宏表下的缓存键，kind 区分预扫描(scan)和解析(parse)的结果
+func cacheKey(kind string, content []byte, macros map[string]*Macro) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "easycpp %s %s\n", Version, kind)
+	names := make([]string, 0, len(macros))
+	for name := range macros {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		// 宏展开后的词法单元使用展开处的位置，替换列表中词法单元的位置不影响结果
+		macro := macros[name]
+		fmt.Fprintf(h, "%q %v %q %v", name, macro.FunctionLike, macro.Params, macro.Variadic)
+		for _, t := range macro.Body {
+			fmt.Fprintf(h, " %d:%q", t.Kind, t.Text)
+		}
+		io.WriteString(h, "\n")
+	}
+	fmt.Fprintf(h, "%d\n", len(content))
+	h.Write(content)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// loadCache 读取缓存的解析结果，缓存不存在或无法使用时 ok 为 false
+func (a *CppAnalyzer) loadCache(key string) (result parseResult, ok bool) {
+	var entry cacheEntry
+	if !a.readCacheEntry(key, &entry) {
+		return parseResult{}, false
+	}
+	result = parseResult{
+		classes:          entry.Classes,
+		includes:         entry.Includes,
+		definitions:      entry.Definitions,
+		diagnostics:      entry.Diagnostics,
+		inlineNamespaces: make(map[string]bool),
+	}
+	for _, ns := range entry.InlineNamespaces {
+		result.inlineNamespaces[ns] = true
+	}
+	return result, true
+}
+
+// storeCache 保存解析结果，必须在 addFile 和跨文件解析修改结果之前调用
+func (a *CppAnalyzer) storeCache(key string, result parseResult) {
+	entry := cacheEntry{
+		Classes:     result.classes,
+		Includes:    result.includes,
+		Definitions: result.definitions,
+		Diagnostics: result.diagnostics,
+	}
+	for ns := range result.inlineNamespaces {
+		entry.InlineNamespaces = append(entry.InlineNamespaces, ns)
+	}
+	sort.Strings(entry.InlineNamespaces)
+	a.writeCacheEntry(key, entry)
+}
+
+// loadScanCache 读取缓存的预扫描结果，缓存不存在或无法使用时返回 nil
+func (a *CppAnalyzer) loadScanCache(key string) *scanResult {
+	var entry scanCacheEntry
+	if !a.readCacheEntry(key, &entry) {
+		return nil
+	}
+	if entry.Macros == nil {
+		entry.Macros = make(map[string]*Macro)
+	}
+	return &scanResult{includes: entry.Includes, macros: entry.Macros}
+}
+
+// storeScanCache 保存预扫描结果
+func (a *CppAnalyzer) storeScanCache(key string, scan *scanResult) {
+	a.writeCacheEntry(key, scanCacheEntry{Includes: scan.includes, Macros: scan.macros})
+}
+
+// readCacheEntry 读取缓存条目到 v 中，并把条目的修改时间更新为现在，作为最近一次使用的时间
+func (a *CppAnalyzer) readCacheEntry(key string, v any) bool {
+	path := filepath.Join(a.cacheDir, key+".json")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	if err := json.Unmarshal(content, v); err != nil {
+		return false
+	}
+	a.cache.use(key)
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return true
+}
+
+// writeCacheEntry 把 v 保存为缓存条目
+// 缓存只用于加速，写入失败时忽略；先写临时文件再改名，并发写入同一个键时不会留下不完整的文件
+func (a *CppAnalyzer) writeCacheEntry(key string, v any) {
+	content, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(a.cacheDir, 0755); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(a.cacheDir, key+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(content)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil || os.Rename(tmp.Name(), filepath.Join(a.cacheDir, key+".json")) != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+	a.cache.use(key)
+}
+
+// pruneCache 在分析结束后清理缓存目录: 条目数超出上限时，按最近一次使用的时间从旧到新删除本次没有用到的条目
+// 文件修改后旧内容的条目不会再被用到，清理使缓存目录不会无限增长
+func (a *CppAnalyzer) pruneCache() {
+	if a.cacheDir == "" {
+		return
+	}
+	entries, err := os.ReadDir(a.cacheDir)
+	if err != nil {
+		return
+	}
+	type staleEntry struct {
+		path    string
+		modTime time.Time
+	}
+	var stale []staleEntry
+	count := 0
+	for _, entry := range entries {
+		key, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || !entry.Type().IsRegular() {
+			continue
+		}
+		count++
+		if a.cache.isUsed(key) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		stale = append(stale, staleEntry{filepath.Join(a.cacheDir, entry.Name()), info.ModTime()})
+	}
+	sort.Slice(stale, func(i, j int) bool {
+		return stale[i].modTime.Before(stale[j].modTime)
+	})
+	for _, entry := range stale {
+		if count <= a.cacheLimit {
+			break
+		}
+		if os.Remove(entry.path) == nil {
+			count--
+		}
+	}
+}
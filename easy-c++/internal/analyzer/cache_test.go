@@ -0,0 +1,152 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestAnalysisCache 测试解析结果的缓存: 结果与不使用缓存时相同，只重新解析有变化的文件
+func TestAnalysisCache(t *testing.T) {
+	tempDir := t.TempDir()
+	projectDir := filepath.Join(tempDir, "project")
+	cacheDir := filepath.Join(tempDir, "cache")
+	files := map[string]string{
+		"base.h": `#pragma once
+inline namespace v1 {
+class Base {
+public:
+    virtual void draw() const = 0;
+};
+}
+`,
+		"shape.h": `#include "base.h"
+#ifdef WITH_CIRCLE
+class Circle : public Base {
+public:
+    void draw() const override;
+};
+#endif
+class Square : public Base {
+public:
+    void draw() const override;
+    double side = 1.0;
+};
+`,
+		"shape.cpp": `#include "shape.h"
+void Square::draw() const {}
+void Square::resize(double s) {}
+`,
+	}
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("创建目录失败: %v", err)
+	}
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+	}
+	for name, content := range files {
+		write(name, content)
+	}
+
+	analyze := func(cached bool, defines ...string) ([]*CppClass, Diagnostics, CacheStats) {
+		a := NewCppAnalyzer()
+		if cached {
+			a.SetCacheDir(cacheDir)
+		}
+		for _, name := range defines {
+			a.Define(name, "1")
+		}
+		classes, err := a.AnalyzeProject(projectDir)
+		if err != nil {
+			t.Fatalf("项目分析失败: %v", err)
+		}
+		return classes, a.Diagnostics(), a.CacheStats()
+	}
+
+	expected, expectedDiagnostics, _ := analyze(false)
+	if _, _, stats := analyze(true); stats != (CacheStats{Misses: 3}) {
+		t.Errorf("首次分析应全部未命中，实际: %+v", stats)
+	}
+	classes, diagnostics, stats := analyze(true)
+	if stats != (CacheStats{Hits: 3}) {
+		t.Errorf("再次分析应全部命中，实际: %+v", stats)
+	}
+	if !reflect.DeepEqual(classes, expected) || !reflect.DeepEqual(diagnostics, expectedDiagnostics) {
+		t.Error("使用缓存的结果应与重新解析的结果相同")
+	}
+	if square := findClassByName(classes, "Square"); square == nil || square.BaseClasses[0].Name != "v1::Base" ||
+		len(square.UndeclaredMethods) != 1 {
+		t.Error("使用缓存时跨文件解析应正常进行")
+	}
+
+	// 修改一个文件只重新解析这个文件
+	write("shape.cpp", files["shape.cpp"]+"void Square::scale(double f) {}\n")
+	if _, _, stats := analyze(true); stats != (CacheStats{Hits: 2, Misses: 1}) {
+		t.Errorf("修改一个文件后应只重新解析该文件，实际: %+v", stats)
+	}
+
+	// 宏的变化使受影响的缓存失效
+	classes, _, stats = analyze(true, "WITH_CIRCLE")
+	if stats.Misses != 3 || findClassByName(classes, "Circle") == nil {
+		t.Errorf("定义宏后应重新解析并得到 Circle，实际: %+v", stats)
+	}
+}
+
+// TestAnalysisCache_Prune 测试缓存保存预扫描结果，分析结束后按上限删除本次没有用到的条目
+func TestAnalysisCache_Prune(t *testing.T) {
+	tempDir := t.TempDir()
+	projectDir := filepath.Join(tempDir, "project")
+	cacheDir := filepath.Join(tempDir, "cache")
+	if err := os.MkdirAll(projectDir, 0755); err != nil {
+		t.Fatalf("创建目录失败: %v", err)
+	}
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+	}
+	write("base.h", "#define BASE_API\nclass Base {};\n")
+	write("shape.h", "#include \"base.h\"\nclass BASE_API Shape : public Base {};\n")
+
+	analyze := func(limit int) []*CppClass {
+		a := NewCppAnalyzer()
+		a.SetCacheDir(cacheDir)
+		a.SetCacheLimit(limit)
+		classes, err := a.AnalyzeProject(projectDir)
+		if err != nil {
+			t.Fatalf("项目分析失败: %v", err)
+		}
+		return classes
+	}
+	entries := func() int {
+		files, err := filepath.Glob(filepath.Join(cacheDir, "*.json"))
+		if err != nil {
+			t.Fatalf("读取缓存目录失败: %v", err)
+		}
+		return len(files)
+	}
+
+	analyze(DefaultCacheLimit)
+	if n := entries(); n != 4 {
+		t.Errorf("每个文件应缓存预扫描和解析两个条目，实际共 %d 个", n)
+	}
+	// 使用缓存的预扫描结果时宏仍然生效
+	if shape := findClassByName(analyze(DefaultCacheLimit), "Shape"); shape == nil || !sliceEqual(baseNames(shape), []string{"Base"}) {
+		t.Error("使用缓存的预扫描结果时应得到 Shape : Base")
+	}
+
+	// 修改后旧内容的条目在上限内保留，超出上限时删除
+	write("shape.h", "#include \"base.h\"\nclass BASE_API Shape : public Base { int x; };\n")
+	analyze(DefaultCacheLimit)
+	if n := entries(); n != 6 {
+		t.Errorf("未超出上限时应保留旧条目，实际共 %d 个", n)
+	}
+	write("shape.h", "#include \"base.h\"\nclass BASE_API Shape : public Base { int y; };\n")
+	analyze(0)
+	if n := entries(); n != 4 {
+		t.Errorf("上限为 0 时应只保留本次用到的条目，实际共 %d 个", n)
+	}
+}
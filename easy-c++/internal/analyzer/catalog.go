@@ -0,0 +1,135 @@
+package analyzer
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+//go:embed catalogs/*.txt
+var builtinCatalogs embed.FS
+
+// DefaultCatalog 分析器默认加载的内置目录
+const DefaultCatalog = "std"
+
+// Catalog 外部类库的类层次目录，描述没有源代码参与分析的类(如标准库、Qt)的基类
+// 继承自目录中的类时，报告可以展示外部基类一直到根的继承链
+type Catalog struct {
+	entries map[string]*CatalogEntry
+}
+
+// CatalogEntry 目录中的一个类
+type CatalogEntry struct {
+	Name   string          // 限定名，如 std::logic_error
+	Bases  []BaseSpecifier // 基类列表
+	Source string          // 所属目录: 内置目录为 std、qt、boost，用户目录为文件路径
+}
+
+// NewCatalog 创建空的目录
+func NewCatalog() *Catalog {
+	return &Catalog{entries: make(map[string]*CatalogEntry)}
+}
+
+// BuiltinCatalogs 返回所有内置目录的名字
+func BuiltinCatalogs() []string {
+	files, _ := builtinCatalogs.ReadDir("catalogs")
+	var names []string
+	for _, file := range files {
+		names = append(names, strings.TrimSuffix(file.Name(), ".txt"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadBuiltin 加载内置目录，如 std、qt、boost
+func (c *Catalog) LoadBuiltin(name string) error {
+	content, err := builtinCatalogs.ReadFile(path.Join("catalogs", name+".txt"))
+	if err != nil {
+		return fmt.Errorf("未知的内置目录 %s (可选: %s)", name, strings.Join(BuiltinCatalogs(), ", "))
+	}
+	return c.parse(string(content), name)
+}
+
+// LoadFile 加载用户提供的目录文件，格式与内置目录相同
+func (c *Catalog) LoadFile(filePath string) error {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("无法打开目录文件 %s: %v", filePath, err)
+	}
+	return c.parse(string(content), filePath)
+}
+
+// Lookup 按限定名查找目录中的类
+func (c *Catalog) Lookup(name string) (*CatalogEntry, bool) {
+	if c == nil {
+		return nil, false
+	}
+	entry, ok := c.entries[strings.TrimPrefix(name, "::")]
+	return entry, ok
+}
+
+// lookup 从 scope 出发逐层向外查找基类名，如在 boost 中写的 noncopyable 找到 boost::noncopyable
+func (c *Catalog) lookup(name, scope string) *CatalogEntry {
+	if strings.HasPrefix(name, "::") {
+		scope = ""
+	}
+	for {
+		qualified := name
+		if scope != "" {
+			qualified = scope + "::" + name
+		}
+		if entry, ok := c.Lookup(qualified); ok {
+			return entry
+		}
+		if scope == "" {
+			return nil
+		}
+		scope = parentScope(scope)
+	}
+}
+
+// parse 解析目录文件的内容，每行为 "限定名" 或 "限定名 : 基类列表"，以 # 开头的行为注释
+// 同名的类以后加载的为准
+func (c *Catalog) parse(content, source string) error {
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		toks := Tokenize(line)
+		colon := findTopLevel(toks, ":")
+		nameToks, baseToks := toks, []Token(nil)
+		if colon >= 0 {
+			nameToks, baseToks = toks[:colon], toks[colon+1:]
+		}
+		name := strings.TrimPrefix(joinTokens(nameToks), "::")
+		if name == "" || (colon >= 0 && len(baseToks) == 0) {
+			return fmt.Errorf("%s:%d: 无法解析的目录条目: %s", source, i+1, line)
+		}
+		bases := parseBaseClause(baseToks, "public")
+		for j := range bases {
+			bases[j].Name = strings.TrimPrefix(bases[j].Name, "::")
+		}
+		c.entries[name] = &CatalogEntry{Name: name, Bases: bases, Source: source}
+	}
+	return nil
+}
+
+// Catalog 返回分析器使用的类层次目录
+func (a *CppAnalyzer) Catalog() *Catalog {
+	return a.catalog
+}
+
+// UseCatalog 加载类层次目录: name 为内置目录名(如 qt、boost)时加载内置目录，否则作为目录文件路径加载
+func (a *CppAnalyzer) UseCatalog(name string) error {
+	for _, builtin := range BuiltinCatalogs() {
+		if name == builtin {
+			return a.catalog.LoadBuiltin(name)
+		}
+	}
+	return a.catalog.LoadFile(name)
+}
@@ -0,0 +1,101 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestCatalog 测试类层次目录的加载，以及外部基类按目录展开到继承链的根
+func TestCatalog(t *testing.T) {
+	for _, name := range BuiltinCatalogs() {
+		if err := NewCatalog().LoadBuiltin(name); err != nil {
+			t.Errorf("内置目录 %s 解析失败: %v", name, err)
+		}
+	}
+	if err := NewCatalog().LoadBuiltin("nope"); err == nil {
+		t.Error("加载不存在的内置目录应返回错误")
+	}
+	if err := NewCatalog().parse("ok::A\nbroken :\n", "bad.txt"); err == nil || !strings.Contains(err.Error(), "bad.txt:2") {
+		t.Errorf("格式错误的目录条目应报告文件和行号，实际: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	catalogPath := filepath.Join(tempDir, "sdk.txt")
+	if err := os.WriteFile(catalogPath, []byte(`# 内部 SDK
+sdk::Object
+sdk::Driver : sdk::Object, protected std::enable_shared_from_this<Driver>
+`), 0644); err != nil {
+		t.Fatalf("创建目录文件失败: %v", err)
+	}
+	sourcePath := filepath.Join(tempDir, "app.h")
+	if err := os.WriteFile(sourcePath, []byte(`
+namespace app {
+    class ConfigError : public std::invalid_argument {};
+    class Log : public std::ostream {};
+    class Device : public sdk::Driver {};
+    class Unknown : public vendor::Thing {};
+}
+namespace boost {
+    class Counter : noncopyable {};
+}
+`), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+
+	a := NewCppAnalyzer()
+	for _, name := range []string{"boost", catalogPath} {
+		if err := a.UseCatalog(name); err != nil {
+			t.Fatalf("加载目录 %s 失败: %v", name, err)
+		}
+	}
+	classes, err := a.AnalyzeFiles([]string{sourcePath})
+	if err != nil {
+		t.Fatalf("多文件分析失败: %v", err)
+	}
+	// boost 中的 noncopyable 按作用域解析为目录中的限定名
+	if counter := findClassByName(classes, "Counter"); counter == nil || !sliceEqual(baseNames(counter), []string{"boost::noncopyable"}) {
+		t.Errorf("Counter 的基类应解析为 boost::noncopyable")
+	}
+
+	externals := make(map[string]*CppClass)
+	for _, external := range ExternalClasses(classes, a.Catalog()) {
+		externals[external.QualifiedName] = external
+	}
+	chains := map[string][]string{
+		"std::invalid_argument":        {"std::logic_error"},
+		"std::logic_error":             {"std::exception"},
+		"std::exception":               nil,
+		"std::ostream":                 {"std::ios"},
+		"std::ios":                     {"std::ios_base"},
+		"std::ios_base":                nil,
+		"sdk::Driver":                  {"sdk::Object", "std::enable_shared_from_this<Driver>"},
+		"sdk::Object":                  nil,
+		"std::enable_shared_from_this": nil,
+		"boost::noncopyable":           nil,
+		"vendor::Thing":                nil,
+	}
+	if len(externals) != len(chains) {
+		t.Errorf("期望 %d 个外部类，实际: %d", len(chains), len(externals))
+	}
+	for name, bases := range chains {
+		external := externals[name]
+		if external == nil {
+			t.Errorf("未找到外部类 %s", name)
+			continue
+		}
+		if !sliceEqual(baseNames(external), bases) {
+			t.Errorf("外部类 %s 的基类不正确，期望: %v，实际: %v", name, bases, baseNames(external))
+		}
+	}
+	if ostream := externals["std::ostream"]; ostream != nil && !ostream.BaseClasses[0].Virtual {
+		t.Error("std::ostream 应虚继承 std::ios")
+	}
+	if driver := externals["sdk::Driver"]; driver != nil && (driver.Catalog != catalogPath || driver.BaseClasses[1].Access != "protected") {
+		t.Errorf("sdk::Driver 的目录或继承方式不正确: %s %v", driver.Catalog, driver.BaseClasses)
+	}
+	if thing := externals["vendor::Thing"]; thing != nil && thing.Catalog != "" {
+		t.Errorf("不在目录中的外部类不应有所属目录: %s", thing.Catalog)
+	}
+}
@@ -0,0 +1,174 @@
+package analyzer
+
+// ClassKind 类按纯虚函数划分的种类
+type ClassKind string
+
+const (
+	ClassConcrete  ClassKind = "concrete"  // 具体类，可以实例化
+	ClassAbstract  ClassKind = "abstract"  // 抽象类，含自身声明或继承而来且未被覆盖的纯虚函数
+	ClassInterface ClassKind = "interface" // 纯接口，只有纯虚函数和虚析构函数，基类也都是接口
+)
+
+// Description 返回种类的中文描述
+func (k ClassKind) Description() string {
+	switch k {
+	case ClassAbstract:
+		return "抽象类"
+	case ClassInterface:
+		return "接口"
+	}
+	return "具体类"
+}
+
+// classifyClasses 在基类解析之后计算每个类的种类和未被覆盖的纯虚函数
+// 纯虚函数按 Method.Signature 匹配覆盖关系；找不到定义的基类不参与计算
+// 同名的类有多个定义时取对派生类可见的最后一个，visible 为 nil 时所有类互相可见
+func classifyClasses(classes []*CppClass, visible func(from, to *CppClass) bool) {
+	known := ClassKeys(classes)
+	byKey := make(map[string][]*CppClass)
+	for _, class := range classes {
+		byKey[classKey(class)] = append(byKey[classKey(class)], class)
+	}
+	bases := func(class *CppClass) []*CppClass {
+		var result []*CppClass
+		for _, baseClass := range class.BaseClasses {
+			candidates := byKey[BaseKey(baseClass, known)]
+			for i := len(candidates) - 1; i >= 0; i-- {
+				if base := candidates[i]; base != class && (visible == nil || visible(class, base)) {
+					result = append(result, base)
+					break
+				}
+			}
+		}
+		return result
+	}
+
+	pure := make(map[*CppClass][]string)
+	var pureVirtuals func(class *CppClass) []string
+	pureVirtuals = func(class *CppClass) []string {
+		if result, done := pure[class]; done {
+			return result
+		}
+		// 先占位，避免继承环导致无限递归
+		pure[class] = nil
+
+		var result []string
+		declared := make(map[string]bool)
+		for _, method := range class.Methods {
+			if method.Kind == MethodDestructor {
+				// 派生类总有自己的析构函数，纯虚析构函数不会遗留给派生类，由 pureDestructor 单独计入声明它的类
+				continue
+			}
+			sig := method.Signature()
+			if method.Pure && !declared[sig] {
+				result = append(result, sig)
+			}
+			declared[sig] = true
+		}
+		for _, base := range bases(class) {
+			for _, sig := range pureVirtuals(base) {
+				if !declared[sig] {
+					result = append(result, sig)
+					declared[sig] = true
+				}
+			}
+		}
+		pure[class] = result
+		return result
+	}
+
+	iface := make(map[*CppClass]bool)
+	var isInterface func(class *CppClass) bool
+	isInterface = func(class *CppClass) bool {
+		if result, done := iface[class]; done {
+			return result
+		}
+		iface[class] = false
+
+		result := hasInterfaceBody(class) && len(pureVirtuals(class)) > 0
+		for _, base := range bases(class) {
+			result = result && isInterface(base)
+		}
+		iface[class] = result
+		return result
+	}
+
+	for _, class := range classes {
+		class.PureVirtuals = pureVirtuals(class)
+		if sig := pureDestructor(class); sig != "" {
+			// pureVirtuals 的结果被派生类共用，不能在其上追加
+			class.PureVirtuals = append([]string{sig}, class.PureVirtuals...)
+		}
+		switch {
+		case isInterface(class):
+			class.Kind = ClassInterface
+		case len(class.PureVirtuals) > 0:
+			class.Kind = ClassAbstract
+		default:
+			class.Kind = ClassConcrete
+		}
+	}
+}
+
+// pureDestructor 返回类自身声明的纯虚析构函数的签名，如 ~Base()，没有时返回空
+// 纯虚析构函数使声明它的类成为抽象类，但不影响派生类
+func pureDestructor(class *CppClass) string {
+	for _, method := range class.Methods {
+		if method.Kind == MethodDestructor && method.Pure {
+			return method.Signature()
+		}
+	}
+	return ""
+}
+
+// hasInterfaceBody 判断类自身是否只声明了纯虚函数和虚析构函数，且没有非静态数据成员
+// = default 或 = delete 的构造函数和赋值运算符不影响判断
+func hasInterfaceBody(class *CppClass) bool {
+	for _, member := range class.Members {
+		if !member.Static {
+			return false
+		}
+	}
+
+	for _, method := range class.Methods {
+		switch {
+		case method.Pure:
+		case method.Kind == MethodDestructor:
+			if !method.Virtual && !method.Override {
+				return false
+			}
+		case method.Defaulted || method.Deleted:
+			if method.Kind != MethodConstructor && method.Name != "operator=" {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// FindImplementers 返回直接或间接继承 iface 的所有非接口类，按类列表中的顺序排列
+func FindImplementers(classes []*CppClass, iface *CppClass) []*CppClass {
+	tree := GetInheritanceTree(classes)
+	visited := map[*CppClass]bool{iface: true}
+	queue := []*CppClass{iface}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range tree[classKey(current)] {
+			if !visited[child] {
+				visited[child] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	var implementers []*CppClass
+	for _, class := range classes {
+		if visited[class] && class != iface && class.Kind != ClassInterface {
+			implementers = append(implementers, class)
+		}
+	}
+	return implementers
+}
@@ -0,0 +1,104 @@
+package analyzer
+
+import (
+	"testing"
+)
+
+// TestClassifyClasses 测试具体类、抽象类和纯接口的识别
+func TestClassifyClasses(t *testing.T) {
+	src := `
+class IShape {
+public:
+    virtual ~IShape() = default;
+    virtual double area() const = 0;
+    virtual void draw() const = 0;
+    IShape& operator=(const IShape&) = delete;
+};
+
+class INamed {
+public:
+    virtual std::string name() const = 0;
+};
+
+class INamedShape : public IShape, public INamed {};
+
+class ShapeBase : public IShape {
+protected:
+    int id;
+public:
+    void draw() const override;
+};
+
+class Circle : public ShapeBase {
+public:
+    double area() const override;
+};
+
+class Square final : public INamedShape {
+public:
+    double area() const override;
+    void draw() const override;
+    std::string name() const override;
+};
+
+class WithData {
+    int value;
+public:
+    virtual void run() = 0;
+};
+
+class Plain {};
+
+class Marker {
+public:
+    virtual ~Marker() = 0;
+};
+
+class Tagged : public Marker {};
+`
+	a := NewCppAnalyzer()
+	classes := a.analyzeSource(src)
+	a.qualifyBaseClasses(classes)
+	classifyClasses(classes, nil)
+
+	expected := map[string]ClassKind{
+		"IShape":      ClassInterface,
+		"INamed":      ClassInterface,
+		"INamedShape": ClassInterface,
+		"ShapeBase":   ClassAbstract,
+		"Circle":      ClassConcrete,
+		"Square":      ClassConcrete,
+		"WithData":    ClassAbstract,
+		"Plain":       ClassConcrete,
+		"Marker":      ClassAbstract,
+		"Tagged":      ClassConcrete,
+	}
+	for name, kind := range expected {
+		class := findClassByName(classes, name)
+		if class == nil {
+			t.Errorf("未找到类 %s", name)
+			continue
+		}
+		if class.Kind != kind {
+			t.Errorf("类 %s 的种类不正确，期望: %s，实际: %s (纯虚函数: %v)", name, kind, class.Kind, class.PureVirtuals)
+		}
+	}
+
+	if marker := findClassByName(classes, "Marker"); !sliceEqual(marker.PureVirtuals, []string{"~Marker()"}) {
+		t.Errorf("Marker 的纯虚析构函数应计入纯虚函数: %v", marker.PureVirtuals)
+	}
+	if shapeBase := findClassByName(classes, "ShapeBase"); !sliceEqual(shapeBase.PureVirtuals, []string{"area() const"}) {
+		t.Errorf("ShapeBase 未实现的纯虚函数不正确: %v", shapeBase.PureVirtuals)
+	}
+	if named := findClassByName(classes, "INamedShape"); len(named.PureVirtuals) != 3 {
+		t.Errorf("INamedShape 应继承3个纯虚函数: %v", named.PureVirtuals)
+	}
+
+	var implementers []string
+	for _, class := range FindImplementers(classes, findClassByName(classes, "IShape")) {
+		implementers = append(implementers, class.Name)
+	}
+	if !sliceEqual(implementers, []string{"ShapeBase", "Circle", "Square"}) {
+		t.Errorf("IShape 的实现类不正确: %v", implementers)
+	}
+}
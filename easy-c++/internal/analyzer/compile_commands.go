@@ -0,0 +1,323 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CompileCommand 表示 compile_commands.json 中的一条编译命令
+type CompileCommand struct {
+	Directory string   `json:"directory"`           // 执行编译命令的工作目录
+	File      string   `json:"file"`                // 翻译单元的源文件，相对路径相对于 Directory
+	Arguments []string `json:"arguments,omitempty"` // 拆分好的命令行参数
+	Command   string   `json:"command,omitempty"`   // 未拆分的命令行，Arguments 为空时使用
+	Output    string   `json:"output,omitempty"`    // 编译输出文件
+}
+
+// Args 返回编译命令的参数列表
+func (c CompileCommand) Args() []string {
+	if len(c.Arguments) > 0 {
+		return c.Arguments
+	}
+	return splitCommandLine(c.Command)
+}
+
+// SourcePath 返回源文件路径，相对路径按 Directory 解析
+func (c CompileCommand) SourcePath() string {
+	return c.resolve(c.File)
+}
+
+// resolve 将命令中的相对路径解析为相对于 Directory 的路径
+func (c CompileCommand) resolve(path string) string {
+	if filepath.IsAbs(path) || c.Directory == "" {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(c.Directory, path)
+}
+
+// compileFlags 从编译命令中提取的与预处理相关的选项
+type compileFlags struct {
+	includePaths []string
+	defines      []macroDefinition
+	std          string // 语言标准，如 c++20、gnu++17
+}
+
+// macroDefinition 命令行上的一个 -D 或 -U
+type macroDefinition struct {
+	name     string
+	value    string
+	undefine bool
+}
+
+// LoadCompileCommands 读取 compile_commands.json，path 为目录时读取其中的 compile_commands.json
+func LoadCompileCommands(path string) ([]CompileCommand, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, "compile_commands.json")
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("无法打开编译数据库 %s: %v", path, err)
+	}
+	var commands []CompileCommand
+	if err := json.Unmarshal(content, &commands); err != nil {
+		return nil, fmt.Errorf("解析编译数据库 %s 时出错: %v", path, err)
+	}
+	return commands, nil
+}
+
+// parseCompileFlags 提取 -I、-isystem、-iquote、-D、-U 和 -std 选项
+// 编译器为 cl 或 clang-cl 时同时识别 MSVC 风格的 /I、/D、/U 和 /std:
+func parseCompileFlags(cmd CompileCommand) compileFlags {
+	var flags compileFlags
+	args := cmd.Args()
+	msvc := false
+	if len(args) > 0 {
+		compiler := strings.ToLower(strings.TrimSuffix(filepath.Base(args[0]), ".exe"))
+		msvc = compiler == "cl" || compiler == "clang-cl"
+	}
+	// value 读取形如 -Ifoo 或 -I foo 的选项值
+	value := func(i *int, prefix string) string {
+		if v := args[*i][len(prefix):]; v != "" {
+			return v
+		}
+		if *i+1 < len(args) {
+			*i++
+			return args[*i]
+		}
+		return ""
+	}
+	// option 返回参数所属的选项前缀，不是需要的选项时返回空
+	option := func(arg string) string {
+		for _, prefix := range []string{"-isystem", "-iquote", "-idirafter", "-I", "-D", "-U", "-std="} {
+			if strings.HasPrefix(arg, prefix) {
+				return prefix
+			}
+		}
+		if msvc {
+			for _, prefix := range []string{"/I", "/D", "/U"} {
+				if rest, ok := strings.CutPrefix(arg, prefix); ok && msvcOptionValue(prefix, rest) {
+					return "-" + prefix[1:]
+				}
+			}
+			if strings.HasPrefix(arg, "/std:") {
+				return "-std:"
+			}
+		}
+		return ""
+	}
+
+	for i := 0; i < len(args); i++ {
+		if msvc && strings.EqualFold(args[i], "/link") {
+			// 之后都是链接器选项
+			break
+		}
+		switch prefix := option(args[i]); prefix {
+		case "-isystem", "-iquote", "-idirafter", "-I":
+			if dir := value(&i, prefix); dir != "" {
+				flags.includePaths = append(flags.includePaths, cmd.resolve(dir))
+			}
+		case "-D":
+			name, val, found := strings.Cut(value(&i, prefix), "=")
+			if !found {
+				val = "1"
+			}
+			if name != "" {
+				flags.defines = append(flags.defines, macroDefinition{name: name, value: val})
+			}
+		case "-U":
+			if name := value(&i, prefix); name != "" {
+				flags.defines = append(flags.defines, macroDefinition{name: name, undefine: true})
+			}
+		case "-std=", "-std:":
+			flags.std = args[i][len(prefix):]
+		}
+	}
+	return flags
+}
+
+// msvcLinkerOptions 以 D、I 开头的 MSVC 链接器选项，写在 cl 的命令行中时不是宏定义或头文件搜索路径
+var msvcLinkerOptions = map[string]bool{
+	"DEBUG": true, "DEBUGTYPE": true, "DEF": true, "DEFAULTLIB": true, "DELAY": true, "DELAYLOAD": true,
+	"DELAYSIGN": true, "DEPENDENTLOADFLAG": true, "DLL": true, "DRIVER": true, "DYNAMICBASE": true,
+	"IDLOUT": true, "IGNORE": true, "IGNOREIDL": true, "ILK": true, "IMPLIB": true, "INCLUDE": true,
+	"INCREMENTAL": true, "INFERASANLIBS": true, "INTEGRITYCHECK": true,
+}
+
+// msvcOptionValue 判断 MSVC 选项 /D、/I、/U 之后紧跟的 rest 是否为选项的值
+// rest 为空时值在下一个参数中；/D、/U 之后须为宏名；/DEBUG、/INCREMENTAL 等链接器选项不是
+func msvcOptionValue(prefix, rest string) bool {
+	if rest == "" {
+		return true
+	}
+	name, _, _ := strings.Cut(prefix[1:]+rest, ":")
+	if msvcLinkerOptions[strings.ToUpper(name)] {
+		return false
+	}
+	return prefix == "/I" || isIdentStart(rest[0])
+}
+
+// cplusplusValue 返回语言标准对应的 __cplusplus 值，无法识别时返回空
+func cplusplusValue(std string) string {
+	std = strings.ToLower(std)
+	for _, prefix := range []string{"gnu++", "c++"} {
+		if strings.HasPrefix(std, prefix) {
+			std = strings.TrimPrefix(std, prefix)
+			break
+		}
+	}
+	switch std {
+	case "98", "03":
+		return "199711L"
+	case "11", "0x":
+		return "201103L"
+	case "14", "1y":
+		return "201402L"
+	case "17", "1z":
+		return "201703L"
+	case "20", "2a":
+		return "202002L"
+	case "23", "2b", "latest":
+		return "202302L"
+	case "26", "2c":
+		return "202400L"
+	}
+	return ""
+}
+
+// defineMacro 在宏表中定义宏，value 为替换列表
+func defineMacro(macros map[string]*Macro, name, value string) {
+	if macro := parseMacroDefinition(name + " " + value); macro != nil {
+		macros[macro.Name] = macro
+	}
+}
+
+// AnalyzeCompileCommands 按编译数据库分析项目
+// 只分析其中列出的翻译单元和它们包含的头文件，每个翻译单元使用自己的 -I、-D 和 -std 选项；
+// 被多个翻译单元包含的头文件只在第一次遇到时解析，其中的类只报告一次
+func (a *CppAnalyzer) AnalyzeCompileCommands(path string) ([]*CppClass, error) {
+	return a.AnalyzeCompileCommandsContext(context.Background(), path)
+}
+
+// AnalyzeCompileCommandsContext 与 AnalyzeCompileCommands 相同，ctx 被取消时停止分析并返回 ctx.Err()
+// 各翻译单元依次分析，同一翻译单元中的文件并发解析
+func (a *CppAnalyzer) AnalyzeCompileCommandsContext(ctx context.Context, path string) ([]*CppClass, error) {
+	commands, err := LoadCompileCommands(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var allClasses []*CppClass
+	a.reset()
+	baseMacros, baseIncludePaths := a.macros, a.includePaths
+	defer func() {
+		a.macros, a.includePaths = baseMacros, baseIncludePaths
+	}()
+
+	parsed := make(map[string]bool)
+	var failed []*FileError
+	for _, cmd := range commands {
+		flags := parseCompileFlags(cmd)
+		a.macros = a.unitMacros(baseMacros, flags)
+		a.includePaths = append(append([]string{}, flags.includePaths...), baseIncludePaths...)
+
+		unitPaths, err := a.scanFiles(ctx, []string{cmd.SourcePath()})
+		if err != nil {
+			return nil, err
+		}
+		var paths []string
+		for _, filePath := range unitPaths {
+			if !parsed[filePath] {
+				parsed[filePath] = true
+				paths = append(paths, filePath)
+			}
+		}
+		classes, unitFailed, err := a.parseFiles(ctx, paths)
+		if err != nil {
+			return nil, err
+		}
+		allClasses = append(allClasses, classes...)
+		failed = append(failed, unitFailed...)
+		if len(failed) > 0 && a.failFast {
+			break
+		}
+	}
+	a.pruneCache()
+
+	// 解析跨文件的继承关系
+	return a.resolveInterFileInheritance(allClasses), analysisError(failed)
+}
+
+// unitMacros 返回翻译单元的初始宏表: 在 base 上应用编译命令的 -std、-D 和 -U，用户通过 Define/Undefine 指定的宏优先
+func (a *CppAnalyzer) unitMacros(base map[string]*Macro, flags compileFlags) map[string]*Macro {
+	macros := make(map[string]*Macro, len(base))
+	for name, macro := range base {
+		macros[name] = macro
+	}
+	if value := cplusplusValue(flags.std); value != "" && !a.userMacros["__cplusplus"] {
+		defineMacro(macros, "__cplusplus", value)
+	}
+	for _, def := range flags.defines {
+		name := def.name
+		if i := strings.IndexByte(name, '('); i >= 0 {
+			name = name[:i]
+		}
+		if a.userMacros[name] {
+			continue
+		}
+		if def.undefine {
+			delete(macros, name)
+		} else {
+			defineMacro(macros, def.name, def.value)
+		}
+	}
+	return macros
+}
+
+// splitCommandLine 按 shell 规则拆分命令行，支持单引号、双引号和反斜杠转义
+func splitCommandLine(command string) []string {
+	var args []string
+	var sb strings.Builder
+	inArg := false
+	var quote byte
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case quote == '\'':
+			if c == '\'' {
+				quote = 0
+			} else {
+				sb.WriteByte(c)
+			}
+		case c == '\\' && i+1 < len(command) && (quote == 0 || strings.IndexByte(`"\$`+"`", command[i+1]) >= 0):
+			i++
+			sb.WriteByte(command[i])
+			inArg = true
+		case quote == '"':
+			if c == '"' {
+				quote = 0
+			} else {
+				sb.WriteByte(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inArg = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inArg {
+				args = append(args, sb.String())
+				sb.Reset()
+				inArg = false
+			}
+		default:
+			sb.WriteByte(c)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, sb.String())
+	}
+	return args
+}
@@ -0,0 +1,117 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestAnalyzeCompileCommands 测试按编译数据库分析，每个翻译单元使用自己的选项，共享的头文件只解析一次
+func TestAnalyzeCompileCommands(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"include/common.h": `#pragma once
+class Base {};
+class Modern {};
+#if __cplusplus >= 202002L
+class Widget : public Modern {};
+#else
+class Widget : public Base {};
+#endif
+`,
+		"src/a.cpp": `#include "common.h"
+#ifdef FEATURE_A
+class FeatureA : public Widget {};
+#endif
+`,
+		"src/b.cpp": `#include <common.h>
+#ifdef FEATURE_A
+class NotInB {};
+#endif
+class UsesB : public Base {};
+`,
+		"src/unlisted.cpp": `class Unlisted {};
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("创建目录失败: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+	}
+
+	buildDir := filepath.Join(tempDir, "build")
+	commands := []CompileCommand{
+		{
+			Directory: buildDir,
+			File:      "../src/a.cpp",
+			Arguments: []string{"clang++", "-std=c++20", "-I", "../include", "-DFEATURE_A", "-c", "../src/a.cpp"},
+		},
+		{
+			Directory: buildDir,
+			File:      filepath.Join(tempDir, "src", "b.cpp"),
+			Command:   `g++ -std=gnu++17 -I../include "-DGREETING=\"hello world\"" -c ../src/b.cpp`,
+		},
+	}
+	content, _ := json.Marshal(commands)
+	if err := os.MkdirAll(buildDir, 0755); err != nil {
+		t.Fatalf("创建目录失败: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(buildDir, "compile_commands.json"), content, 0644); err != nil {
+		t.Fatalf("创建编译数据库失败: %v", err)
+	}
+
+	classes, err := NewCppAnalyzer().AnalyzeCompileCommands(buildDir)
+	if err != nil {
+		t.Fatalf("按编译数据库分析失败: %v", err)
+	}
+
+	count := make(map[string]int)
+	for _, class := range classes {
+		count[class.Name]++
+	}
+	for _, name := range []string{"Base", "Modern", "Widget", "FeatureA", "UsesB"} {
+		if count[name] != 1 {
+			t.Errorf("类 %s 应报告1次，实际: %d", name, count[name])
+		}
+	}
+	for _, name := range []string{"NotInB", "Unlisted"} {
+		if count[name] != 0 {
+			t.Errorf("不应找到类 %s", name)
+		}
+	}
+	// common.h 第一次由 a.cpp 以 -std=c++20 包含
+	if widget := findClassByName(classes, "Widget"); widget != nil && !sliceEqual(baseNames(widget), []string{"Modern"}) {
+		t.Errorf("Widget 应按 -std=c++20 继承 Modern，实际: %v", baseNames(widget))
+	}
+
+	flags := parseCompileFlags(commands[1])
+	if flags.std != "gnu++17" || len(flags.includePaths) != 1 || flags.includePaths[0] != filepath.Join(tempDir, "include") {
+		t.Errorf("编译选项解析不正确: %+v", flags)
+	}
+	if len(flags.defines) != 1 || flags.defines[0].name != "GREETING" || flags.defines[0].value != `"hello world"` {
+		t.Errorf("-D 选项解析不正确: %+v", flags.defines)
+	}
+	msvc := parseCompileFlags(CompileCommand{Directory: buildDir, Command: `cl.exe /std:c++latest /I..\include /DWIN=1 /c a.cpp`})
+	if msvc.std != "c++latest" || len(msvc.includePaths) != 1 || len(msvc.defines) != 1 {
+		t.Errorf("MSVC 风格的编译选项解析不正确: %+v", msvc)
+	}
+	linker := parseCompileFlags(CompileCommand{Directory: buildDir,
+		Command: `cl.exe /DEBUG /DLL /INCREMENTAL:NO /D:bad /D1X /DDEBUG /U NDEBUG /D _WIN32 /I /include /c a.cpp /link /DEF:a.def /Ilib`})
+	var defines []string
+	for _, d := range linker.defines {
+		defines = append(defines, fmt.Sprintf("%s=%s/%v", d.name, d.value, d.undefine))
+	}
+	if !sliceEqual(defines, []string{"DEBUG=1/false", "NDEBUG=/true", "_WIN32=1/false"}) || len(linker.includePaths) != 1 {
+		t.Errorf("MSVC 的链接器选项不应被当作宏定义或搜索路径: %v %v", defines, linker.includePaths)
+	}
+	unix := parseCompileFlags(CompileCommand{Command: "c++ -c /Users/dev/src/a.cpp"})
+	if len(unix.defines) != 0 {
+		t.Errorf("非 MSVC 编译器的 /U 开头的路径不应被当作选项: %+v", unix.defines)
+	}
+}
@@ -1,220 +1,287 @@
 package analyzer
 
 import (
-	"bufio"
+	"context"
 	"fmt"
-	"os"
-	"path/filepath"
-	"regexp"
+	"io/fs"
+	"runtime"
 	"strings"
 )
 
-// CppClass 表示一个C++类
+// CppClass 表示一个C++类(含 struct 和 union)
 type CppClass struct {
-	Name        string   // 类名
-	BaseClasses []string // 基类列表
-	Members     []string // 成员变量
-	Methods     []string // 成员方法
-	LineNumber  int      // 类定义开始的行号
-	FilePath    string   // 类定义所在的文件路径
+	Name          string          // 类名(不含作用域)
+	QualifiedName string          // 完全限定名，如 ui::Widget，特化带模板实参，如 Foo<int>
+	Namespace     string          // 所在命名空间，全局命名空间为空
+	ClassKey      string          // 类关键字: class、struct 或 union
+	BaseClasses   []BaseSpecifier // 基类列表
+	Members       []Member        // 成员变量
+	Methods       []Method        // 成员方法
+	LineNumber    int             // 类定义开始的行号
+	Column        int             // 类定义开始的列号
+	FilePath      string          // 类定义所在的文件路径
+	FileKind      FileKind        // 类定义所在文件的种类: 头文件、源文件或模块接口单元，无法识别时为空
+
+	EnclosingClass    string // 嵌套类的外围类限定名
+	EnclosingFunction string // 局部类所在函数的限定名
+
+	TemplateParams     []TemplateParam // 类模板的模板形参
+	SpecializationArgs []string        // 显式或偏特化的模板实参
+	PrimaryTemplate    string          // 特化对应的主模板限定名
+
+	Kind         ClassKind // 具体类、抽象类或纯接口
+	PureVirtuals []string  // 未被覆盖的纯虚函数签名，含继承而来的
+
+	External bool   // 外部类: 被引用为基类但未在分析的文件中找到定义，如 std::runtime_error
+	Catalog  string // 外部类所属的类层次目录，如 std；不在任何目录中时为空
+
+	UndeclaredMethods []Method // 在类体外定义但类中没有对应声明的方法
+
+	AnonymousNamespace bool // 是否位于匿名命名空间中，只在所在翻译单元内可见
+	Conflicting        bool // 同名类在别处有不同的定义，违反单一定义规则
+}
+
+// BaseSpecifier 表示基类子句中的一个基类
+type BaseSpecifier struct {
+	Name         string   // 基类名(不含模板实参)，解析后为限定名
+	TemplateArgs []string // 模板实参，如 Base<Derived> 中的 Derived
+	Access       string   // 继承方式: public、protected 或 private
+	Virtual      bool     // 是否为虚继承
+	Variadic     bool     // 是否为形参包展开，如 Bases...
+	External     bool     // 是否为未在分析的文件中找到定义的外部基类
+}
+
+// FullName 返回带模板实参的基类名，如 Base<Derived>
+func (b BaseSpecifier) FullName() string {
+	if len(b.TemplateArgs) == 0 {
+		return b.Name
+	}
+	return b.Name + "<" + strings.Join(b.TemplateArgs, ", ") + ">"
+}
+
+// String 返回基类说明符的源代码形式，如 virtual public Base<T>
+func (b BaseSpecifier) String() string {
+	s := b.FullName()
+	if b.Variadic {
+		s += "..."
+	}
+	if b.Access != "" {
+		s = b.Access + " " + s
+	}
+	if b.Virtual {
+		s = "virtual " + s
+	}
+	return s
 }
 
 // CppAnalyzer C++代码分析器
 type CppAnalyzer struct {
-	classRegex     *regexp.Regexp
-	inheritRegex   *regexp.Regexp
-	memberRegex    *regexp.Regexp
-	methodRegex    *regexp.Regexp
-	commentRegex   *regexp.Regexp
-	blockCommentRe *regexp.Regexp
+	inlineNamespaces map[string]bool         // 本次分析中遇到的内联命名空间
+	macros           map[string]*Macro       // 预定义宏和用户通过 Define 定义的宏
+	userMacros       map[string]bool         // 用户通过 Define/Undefine 指定过的宏名
+	scanned          map[string]*scannedFile // 多文件分析时预先扫描各文件的结果
+	includePaths     []string                // 头文件搜索路径
+	includeGraph     *IncludeGraph           // 本次分析得到的包含关系图
+	knownFiles       map[string]string       // 参与分析的文件，键为绝对路径，值为分析时使用的路径
+	catalog          *Catalog                // 外部类库的类层次目录
+	definitions      []MethodDefinition      // 本次分析中遇到的类体外成员函数定义
+	redefinitions    []ClassRedefinition     // 本次分析中在多处定义的类
+	diagnostics      Diagnostics             // 本次分析产生的诊断
+	failFast         bool                    // 遇到分析失败的文件时是否立即停止
+	jobs             int                     // 并发解析文件的最大数量
+	cacheDir         string                  // 缓存解析结果的目录，为空时不使用缓存
+	cacheLimit       int                     // 缓存目录中最多保留的条目数
+	includeRules     []globRule              // 分析项目时作为起点的文件
+	excludeRules     []globRule              // 分析项目时排除的文件和目录
+	useGitignore     bool                    // 分析项目时是否遵循 .gitignore
+	filter           *fileFilter             // 本次项目分析选择文件的规则，不是项目分析时为 nil
+	extensions       map[string]FileKind     // 识别为C++文件的扩展名及其种类
+	fsys             fs.FS                   // 本次分析读取文件的文件系统，为 nil 时读取操作系统的文件
+	cache            *cacheStats             // 本次分析中缓存的命中情况
 }
 
 // NewCppAnalyzer 创建新的分析器实例
+// 默认加载内置的标准库目录
 func NewCppAnalyzer() *CppAnalyzer {
-	return &CppAnalyzer{
-		// 匹配类定义 (支持继承)
-		classRegex: regexp.MustCompile(`class\s+(\w+)(?:\s*:\s*(.+?))?\s*\{`),
-		// 匹配继承关系
-		inheritRegex: regexp.MustCompile(`(?:public|private|protected)?\s*(\w+)`),
-		// 匹配成员变量
-		memberRegex: regexp.MustCompile(`^\s*(?:private|public|protected)?\s*(\w+(?:\s*\*)?)\s+(\w+)(?:\[.*?\])?(?:\s*=.*?)?;`),
-		// 匹配成员方法
-		methodRegex: regexp.MustCompile(`^\s*(?:virtual\s+)?(?:static\s+)?(?:inline\s+)?(\w+(?:\s*\*)?)\s+(\w+)\s*\([^)]*\)(?:\s*const)?(?:\s*=\s*0)?(?:\s*override)?`),
-		// 匹配单行注释
-		commentRegex: regexp.MustCompile(`//.*$`),
-		// 匹配块注释
-		blockCommentRe: regexp.MustCompile(`/\*.*?\*/`),
+	catalog := NewCatalog()
+	// 内置目录随程序一起发布，由测试保证可以正确解析
+	_ = catalog.LoadBuiltin(DefaultCatalog)
+	a := &CppAnalyzer{
+		inlineNamespaces: make(map[string]bool),
+		macros:           predefinedMacros(),
+		userMacros:       make(map[string]bool),
+		includeGraph:     newIncludeGraph(),
+		knownFiles:       make(map[string]string),
+		catalog:          catalog,
+		jobs:             runtime.GOMAXPROCS(0),
+		cache:            &cacheStats{},
+		cacheLimit:       DefaultCacheLimit,
+		extensions:       make(map[string]FileKind, len(DefaultExtensions)),
 	}
+	for ext, kind := range DefaultExtensions {
+		a.extensions[ext] = kind
+	}
+	return a
 }
 
 // AnalyzeFile 分析指定的C++文件
+// 基类名在文件内按作用域解析为限定名，文件内找不到的基类保持原样并标记为外部基类
 func (a *CppAnalyzer) AnalyzeFile(filePath string) ([]*CppClass, error) {
-	file, err := os.Open(filePath)
+	a.reset()
+	a.scanned = nil
+	a.registerFiles([]string{filePath})
+	classes, err := a.parseFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("无法打开文件 %s: %v", filePath, err)
+		return nil, err
 	}
-	defer file.Close()
-
-	var classes []*CppClass
-	scanner := bufio.NewScanner(file)
-	lineNumber := 0
-	inBlockComment := false
+	a.pruneCache()
 
-	for scanner.Scan() {
-		lineNumber++
-		line := scanner.Text()
-
-		// 处理块注释
-		line = a.removeComments(line, &inBlockComment)
-
-		// 跳过空行和纯注释行
-		if strings.TrimSpace(line) == "" {
-			continue
-		}
+	return a.resolveInterFileInheritance(classes), nil
+}
 
-		// 查找类定义
-		if matches := a.classRegex.FindStringSubmatch(line); matches != nil {
-			class := &CppClass{
-				Name:       matches[1],
-				LineNumber: lineNumber,
-			}
+// reset 清除上一次分析的状态
+func (a *CppAnalyzer) reset() {
+	a.inlineNamespaces = make(map[string]bool)
+	a.includeGraph = newIncludeGraph()
+	a.knownFiles = make(map[string]string)
+	a.definitions = nil
+	a.redefinitions = nil
+	a.diagnostics = nil
+	a.cache = &cacheStats{}
+	a.filter = nil
+}
 
-			// 解析继承关系
-			if len(matches) > 2 && matches[2] != "" {
-				class.BaseClasses = a.parseInheritance(matches[2])
-			}
+// parseResult 解析单个文件得到的结果
+type parseResult struct {
+	classes          []*CppClass        // 类定义
+	includes         []Include          // 活动分支中的 #include 指令，尚未解析路径
+	definitions      []MethodDefinition // 类体外的成员函数定义
+	diagnostics      []Diagnostic       // 预处理中发现的问题
+	inlineNamespaces map[string]bool    // 遇到的内联命名空间
+}
 
-			// 读取类体内容
-			a.parseClassBody(scanner, class, &lineNumber, &inBlockComment)
-			classes = append(classes, class)
-		}
+// parseFile 读取并解析单个文件，记录其包含关系，不做基类解析
+func (a *CppAnalyzer) parseFile(filePath string) ([]*CppClass, error) {
+	result, err := a.readFile(filePath)
+	if err != nil {
+		return nil, err
 	}
+	return a.addFile(filePath, a.FileKind(filePath), result), nil
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("读取文件时出错: %v", err)
+// readFile 读取并解析单个文件，解析中发生的 panic 作为该文件的错误返回
+// 除缓存计数外不修改分析器的状态，可以并发调用
+func (a *CppAnalyzer) readFile(filePath string) (result parseResult, err error) {
+	content, err := a.readSource(filePath)
+	if err != nil {
+		return parseResult{}, fmt.Errorf("无法打开文件 %s: %v", filePath, err)
 	}
-
-	return classes, nil
+	defer recoverPanic(&err)
+	return a.parseContent(filePath, content), nil
 }
 
-// removeComments 移除代码中的注释
-func (a *CppAnalyzer) removeComments(line string, inBlockComment *bool) string {
-	result := line
-
-	// 处理块注释
-	if *inBlockComment {
-		if idx := strings.Index(result, "*/"); idx != -1 {
-			*inBlockComment = false
-			result = result[idx+2:]
-		} else {
-			return ""
-		}
+// parseContent 解析文件的内容，设置了缓存目录时优先使用缓存的结果
+func (a *CppAnalyzer) parseContent(filePath string, content []byte) parseResult {
+	macros := a.macrosFor(filePath)
+	if a.cacheDir == "" {
+		return parseSource(content, macros)
 	}
 
-	// 查找块注释开始
-	for {
-		startIdx := strings.Index(result, "/*")
-		if startIdx == -1 {
-			break
-		}
-
-		endIdx := strings.Index(result[startIdx:], "*/")
-		if endIdx == -1 {
-			*inBlockComment = true
-			result = result[:startIdx]
-			break
-		} else {
-			result = result[:startIdx] + result[startIdx+endIdx+2:]
-		}
+	key := cacheKey("parse", content, macros)
+	if result, ok := a.loadCache(key); ok {
+		a.cache.hits.Add(1)
+		return result
 	}
-
-	// 移除单行注释
-	result = a.commentRegex.ReplaceAllString(result, "")
-
+	a.cache.misses.Add(1)
+	result := parseSource(content, macros)
+	a.storeCache(key, result)
 	return result
 }
 
-// parseInheritance 解析继承关系字符串
-func (a *CppAnalyzer) parseInheritance(inheritanceStr string) []string {
-	var baseClasses []string
-
-	// 按逗号分割多重继承
-	parts := strings.Split(inheritanceStr, ",")
-
-	for _, part := range parts {
-		part = strings.TrimSpace(part)
-		// 使用正则表达式提取基类名
-		if matches := a.inheritRegex.FindStringSubmatch(part); matches != nil {
-			baseClasses = append(baseClasses, matches[1])
-		}
+// addFile 把文件的解析结果并入本次分析: 解析并记录包含关系，收集类体外定义和诊断，返回文件中的类
+// kind 为文件的种类，记录在其中的类上
+func (a *CppAnalyzer) addFile(filePath string, kind FileKind, result parseResult) []*CppClass {
+	for ns := range result.inlineNamespaces {
+		a.inlineNamespaces[ns] = true
 	}
-
-	return baseClasses
+	for _, d := range result.diagnostics {
+		d.File = filePath
+		a.diagnostics = append(a.diagnostics, d)
+	}
+	for i := range result.definitions {
+		result.definitions[i].FilePath = filePath
+	}
+	a.definitions = append(a.definitions, result.definitions...)
+	for i := range result.includes {
+		result.includes[i].Resolved = a.resolveInclude(filePath, result.includes[i])
+	}
+	a.includeGraph.addFile(filePath, result.includes)
+	for _, class := range result.classes {
+		class.FilePath = filePath
+		class.FileKind = kind
+	}
+	return result.classes
 }
 
-// parseClassBody 解析类体内容
-func (a *CppAnalyzer) parseClassBody(scanner *bufio.Scanner, class *CppClass, lineNumber *int, inBlockComment *bool) {
-	braceCount := 1 // 已经遇到了开始的 {
-
-	for scanner.Scan() && braceCount > 0 {
-		*lineNumber++
-		line := scanner.Text()
-
-		// 处理注释
-		line = a.removeComments(line, inBlockComment)
-		if strings.TrimSpace(line) == "" {
-			continue
-		}
-
-		// 计算大括号
-		braceCount += strings.Count(line, "{")
-		braceCount -= strings.Count(line, "}")
-
-		if braceCount <= 0 {
-			break
-		}
-
-		// 跳过访问修饰符行
-		trimmed := strings.TrimSpace(line)
-		if trimmed == "public:" || trimmed == "private:" || trimmed == "protected:" {
-			continue
-		}
-
-		// 尝试匹配成员变量
-		if matches := a.memberRegex.FindStringSubmatch(line); matches != nil {
-			member := fmt.Sprintf("%s %s", matches[1], matches[2])
-			class.Members = append(class.Members, member)
-			continue
-		}
+// parseSource 解码文件内容后预处理并解析，无法识别编码时按原样解析并记录警告
+func parseSource(content []byte, macros map[string]*Macro) parseResult {
+	text, _, ok := decodeSource(content)
+	result := parseTokens(Tokenize(text), macros)
+	if !ok {
+		result.diagnostics = append(result.diagnostics, Diagnostic{
+			Severity: SeverityWarning,
+			Code:     CodeInvalidEncoding,
+			Message:  "文件不是有效的 UTF-8、UTF-16 或 GB18030 编码，已按原样分析",
+		})
+	}
+	return result
+}
 
-		// 尝试匹配成员方法
-		if matches := a.methodRegex.FindStringSubmatch(line); matches != nil {
-			method := fmt.Sprintf("%s %s(...)", matches[1], matches[2])
-			class.Methods = append(class.Methods, method)
-		}
+// parseTokens 以 macros 为初始宏表预处理并解析词法单元序列
+func parseTokens(tokens []Token, macros map[string]*Macro) parseResult {
+	pp := newPreprocessor(macros)
+	p := newParser(pp.run(tokens))
+	classes := p.parse()
+	return parseResult{
+		classes:          classes,
+		includes:         pp.includes,
+		definitions:      p.definitions,
+		diagnostics:      pp.diagnostics,
+		inlineNamespaces: p.inlineNamespaces,
 	}
 }
 
 // GetInheritanceTree 构建继承树
+// 键为基类的类名；基类恰好是某个模板特化时以特化为键，否则去掉模板实参
 func GetInheritanceTree(classes []*CppClass) map[string][]*CppClass {
 	tree := make(map[string][]*CppClass)
+	known := ClassKeys(classes)
 
 	// 为每个基类建立子类列表
 	for _, class := range classes {
 		for _, baseClass := range class.BaseClasses {
-			tree[baseClass] = append(tree[baseClass], class)
+			key := BaseKey(baseClass, known)
+			tree[key] = append(tree[key], class)
 		}
 	}
 
 	return tree
 }
 
-// FindRootClasses 找到所有根类(没有基类的类)
+// FindRootClasses 找到所有根类(没有基类，或基类都不在类列表中的类)
 func FindRootClasses(classes []*CppClass) []*CppClass {
 	var roots []*CppClass
+	known := ClassKeys(classes)
 
 	for _, class := range classes {
-		if len(class.BaseClasses) == 0 {
+		isRoot := true
+		for _, baseClass := range class.BaseClasses {
+			if known[BaseKey(baseClass, known)] {
+				isRoot = false
+				break
+			}
+		}
+		if isRoot {
 			roots = append(roots, class)
 		}
 	}
@@ -222,94 +289,198 @@ func FindRootClasses(classes []*CppClass) []*CppClass {
 	return roots
 }
 
-// AnalyzeProject 分析整个C++项目目录
-func (a *CppAnalyzer) AnalyzeProject(projectPath string) ([]*CppClass, error) {
-	var allClasses []*CppClass
-
-	// 遍历项目目录查找C++文件
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
+// FindNestedClasses 返回直接嵌套在 outer 中的类
+func FindNestedClasses(classes []*CppClass, outer *CppClass) []*CppClass {
+	var nested []*CppClass
+	for _, class := range classes {
+		if class.EnclosingClass != "" && class.EnclosingClass == outer.QualifiedName {
+			nested = append(nested, class)
 		}
+	}
+	return nested
+}
 
-		// 检查是否为C++文件
-		if !info.IsDir() && a.isCppFile(path) {
-			classes, err := a.AnalyzeFile(path)
-			if err != nil {
-				fmt.Printf("警告: 分析文件 %s 时出错: %v\n", path, err)
-				return nil // 继续处理其他文件
+// ExternalClasses 为外部基类创建外部类节点，按首次被引用的顺序排列，每个名字只创建一个
+// 外部基类在 catalog 中有记录时，节点带有目录中的基类，并继续为这些基类创建节点，直到继承链的根；catalog 可以为 nil
+// 把结果追加到类列表后，继承自外部基类的类在继承树中挂在外部类节点下，而不再被当作根类
+func ExternalClasses(classes []*CppClass, catalog *Catalog) []*CppClass {
+	known := ClassKeys(classes)
+	seen := make(map[string]bool)
+	var externals []*CppClass
+	add := func(bases []BaseSpecifier) {
+		for _, baseClass := range bases {
+			key := BaseKey(baseClass, known)
+			if !baseClass.External || known[key] || seen[key] {
+				continue
 			}
-
-			// 为每个类添加文件路径信息
-			for _, class := range classes {
-				class.FilePath = path
+			seen[key] = true
+			parts := splitQualifiedName(key)
+			external := &CppClass{
+				Name:          parts[len(parts)-1],
+				QualifiedName: key,
+				Namespace:     parentScope(key),
+				External:      true,
 			}
-
-			allClasses = append(allClasses, classes...)
+			if entry, ok := catalog.Lookup(key); ok {
+				external.Catalog = entry.Source
+				for _, base := range entry.Bases {
+					base.External = true
+					external.BaseClasses = append(external.BaseClasses, base)
+				}
+			}
+			externals = append(externals, external)
 		}
+	}
+	for _, class := range classes {
+		add(class.BaseClasses)
+	}
+	// 逐个展开外部类节点在目录中的基类，新节点追加在末尾
+	for i := 0; i < len(externals); i++ {
+		add(externals[i].BaseClasses)
+	}
+	return externals
+}
 
-		return nil
-	})
+// classKey 返回类在继承树中的键
+func classKey(class *CppClass) string {
+	if class.QualifiedName != "" {
+		return class.QualifiedName
+	}
+	return class.Name
+}
 
+// ClassKeys 返回类列表中所有类在继承树中的键
+func ClassKeys(classes []*CppClass) map[string]bool {
+	known := make(map[string]bool)
+	for _, class := range classes {
+		known[classKey(class)] = true
+	}
+	return known
+}
+
+// BaseKey 返回基类在继承树中对应的键，known 为 ClassKeys 的结果
+// 基类恰好是某个模板特化时为特化的键，否则为基类名
+func BaseKey(baseClass BaseSpecifier, known map[string]bool) string {
+	if fullName := baseClass.FullName(); known[fullName] {
+		return fullName
+	}
+	return baseClass.Name
+}
+
+// AnalyzeProject 分析整个C++项目目录
+// 无法访问的子目录和分析失败的文件汇总在返回的 *AnalysisError 中，同时返回其余文件的分析结果
+func (a *CppAnalyzer) AnalyzeProject(projectPath string) ([]*CppClass, error) {
+	return a.AnalyzeProjectContext(context.Background(), projectPath)
+}
+
+// AnalyzeProjectContext 与 AnalyzeProject 相同，ctx 被取消时停止分析并返回 ctx.Err()
+func (a *CppAnalyzer) AnalyzeProjectContext(ctx context.Context, projectPath string) ([]*CppClass, error) {
+	a.reset()
+	return a.analyzeProject(ctx, projectPath)
+}
+
+// analyzeProject 分析 projectPath 目录下的项目，调用前需要重置分析器的状态
+func (a *CppAnalyzer) analyzeProject(ctx context.Context, projectPath string) ([]*CppClass, error) {
+	// 遍历项目目录查找C++文件
+	a.filter = &fileFilter{root: projectPath, includes: a.includeRules, excludes: a.excludeRules}
+	paths, failed, err := a.walkProject(ctx, projectPath)
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
 	if err != nil {
 		return nil, fmt.Errorf("遍历项目目录时出错: %v", err)
 	}
 
-	// 解析跨文件的继承关系
-	a.resolveInterFileInheritance(allClasses)
+	if len(failed) > 0 && a.failFast {
+		return nil, analysisError(failed)
+	}
 
-	return allClasses, nil
-}
+	// 目录外被包含的头文件也参与分析
+	paths, err = a.scanFiles(ctx, paths)
+	if err != nil {
+		return nil, err
+	}
+	allClasses, parseFailed, err := a.parseFiles(ctx, paths)
+	if err != nil {
+		return nil, err
+	}
+	failed = append(failed, parseFailed...)
+	a.pruneCache()
 
-// isCppFile 检查文件是否为C++源文件
-func (a *CppAnalyzer) isCppFile(filePath string) bool {
-	ext := strings.ToLower(filepath.Ext(filePath))
-	return ext == ".cpp" || ext == ".cxx" || ext == ".cc" || ext == ".c++" ||
-		ext == ".h" || ext == ".hpp" || ext == ".hxx" || ext == ".h++"
+	// 解析跨文件的继承关系
+	return a.resolveInterFileInheritance(allClasses), analysisError(failed)
 }
 
 // resolveInterFileInheritance 解析跨文件的继承关系
-func (a *CppAnalyzer) resolveInterFileInheritance(classes []*CppClass) {
-	// 创建类名到类对象的映射
-	classMap := make(map[string]*CppClass)
-	for _, class := range classes {
-		classMap[class.Name] = class
-	}
+// 基类名从派生类所在作用域出发查找并替换为限定名，未找到定义的基类保留并标记为外部基类
+// 然后合并同名类的相同定义，并把类体外的成员函数定义关联到类中的声明，返回合并后的类列表
+func (a *CppAnalyzer) resolveInterFileInheritance(classes []*CppClass) []*CppClass {
+	a.qualifyBaseClasses(classes)
+	classifyClasses(classes, a.includeGraph.Visible)
+	classes = a.checkRedefinitions(classes)
+	a.linkMethodDefinitions(classes)
+	return classes
+}
+
+// qualifyBaseClasses 按C++名字查找规则将基类名替换为已知类的限定名，并把模板特化关联到主模板
+// 只在派生类所在翻译单元可见的类中查找；以模板形参为基类(如 T、Bases...)时保持原样；
+// 未能解析的基类标记为外部基类，能在类层次目录中找到时替换为目录中的限定名
+func (a *CppAnalyzer) qualifyBaseClasses(classes []*CppClass) {
+	index := newClassIndex(classes, a.inlineNamespaces, a.includeGraph.Visible)
 
-	// 验证和修正继承关系
 	for _, class := range classes {
-		var validBaseClasses []string
-		for _, baseName := range class.BaseClasses {
-			if _, exists := classMap[baseName]; exists {
-				validBaseClasses = append(validBaseClasses, baseName)
-			} else {
-				fmt.Printf("警告: 类 %s 的基类 %s 未找到定义\n", class.Name, baseName)
+		scope := parentScope(class.QualifiedName)
+		if len(class.SpecializationArgs) > 0 {
+			if primary := index.lookup(TemplateName(class.QualifiedName), "", class); primary != nil {
+				class.PrimaryTemplate = primary.QualifiedName
 			}
 		}
-		class.BaseClasses = validBaseClasses
+
+		for i := range class.BaseClasses {
+			baseClass := &class.BaseClasses[i]
+			if class.IsTemplateParam(baseClass.Name) {
+				continue
+			}
+			if base := index.lookup(baseClass.FullName(), scope, class); base != nil {
+				// 与某个特化完全匹配
+				baseClass.Name = TemplateName(base.QualifiedName)
+				continue
+			}
+			if base := index.lookup(baseClass.Name, scope, class); base != nil {
+				baseClass.Name = base.QualifiedName
+				continue
+			}
+			if entry := a.catalog.lookup(baseClass.Name, scope); entry != nil {
+				// 目录中的外部类，使用目录中的限定名
+				baseClass.Name = entry.Name
+			}
+			baseClass.External = true
+		}
 	}
 }
 
 // AnalyzeFiles 分析多个指定的C++文件
+// 有文件分析失败时返回其余文件的分析结果和汇总了所有失败文件的 *AnalysisError
 func (a *CppAnalyzer) AnalyzeFiles(filePaths []string) ([]*CppClass, error) {
-	var allClasses []*CppClass
-
-	for _, filePath := range filePaths {
-		classes, err := a.AnalyzeFile(filePath)
-		if err != nil {
-			return nil, fmt.Errorf("分析文件 %s 时出错: %v", filePath, err)
-		}
+	return a.AnalyzeFilesContext(context.Background(), filePaths)
+}
 
-		// 为每个类添加文件路径信息
-		for _, class := range classes {
-			class.FilePath = filePath
-		}
+// AnalyzeFilesContext 与 AnalyzeFiles 相同，ctx 被取消时停止分析并返回 ctx.Err()
+func (a *CppAnalyzer) AnalyzeFilesContext(ctx context.Context, filePaths []string) ([]*CppClass, error) {
+	a.reset()
 
-		allClasses = append(allClasses, classes...)
+	// 被包含的头文件也参与分析
+	paths, err := a.scanFiles(ctx, filePaths)
+	if err != nil {
+		return nil, err
 	}
+	allClasses, failed, err := a.parseFiles(ctx, paths)
+	if err != nil {
+		return nil, err
+	}
+	a.pruneCache()
 
 	// 解析跨文件的继承关系
-	a.resolveInterFileInheritance(allClasses)
-
-	return allClasses, nil
+	return a.resolveInterFileInheritance(allClasses), analysisError(failed)
 }
@@ -88,7 +88,7 @@ public:
 	if dog == nil {
 		t.Error("未找到Dog类")
 	} else {
-		if len(dog.BaseClasses) != 1 || dog.BaseClasses[0] != "Animal" {
+		if len(dog.BaseClasses) != 1 || dog.BaseClasses[0].Name != "Animal" {
 			t.Errorf("Dog应该继承自Animal，实际继承自: %v", dog.BaseClasses)
 		}
 	}
@@ -98,7 +98,7 @@ public:
 	if workingDog == nil {
 		t.Error("未找到WorkingDog类")
 	} else {
-		if len(workingDog.BaseClasses) != 1 || workingDog.BaseClasses[0] != "Dog" {
+		if len(workingDog.BaseClasses) != 1 || workingDog.BaseClasses[0].Name != "Dog" {
 			t.Errorf("WorkingDog应该继承自Dog，实际继承自: %v", workingDog.BaseClasses)
 		}
 	}
@@ -112,25 +112,36 @@ func TestCppAnalyzer_ParseInheritance(t *testing.T) {
 		input    string
 		expected []string
 	}{
-		{"public Animal", []string{"Animal"}},
-		{"private Animal", []string{"Animal"}},
-		{"protected Animal", []string{"Animal"}},
-		{"public Animal, private Mammal", []string{"Animal", "Mammal"}},
-		{"Animal", []string{"Animal"}},
+		{"public Animal", []string{"public Animal"}},
+		{"private Animal", []string{"private Animal"}},
+		{"protected Animal", []string{"protected Animal"}},
+		{"public Animal, private Mammal", []string{"public Animal", "private Mammal"}},
+		{"Animal", []string{"private Animal"}},
+		{"virtual public Base", []string{"virtual public Base"}},
+		{"public virtual Base, Other", []string{"virtual public Base", "private Other"}},
+		{"protected virtual ns::Base<int, char>", []string{"virtual protected ns::Base<int, char>"}},
+		{"public Outer<int>::Inner", []string{"public Outer<int>::Inner"}},
+		{"public Bases...", []string{"public Bases..."}},
 	}
 
 	for _, test := range tests {
-		result := analyzer.parseInheritance(test.input)
+		var result []string
+		for _, base := range analyzer.parseInheritance(test.input) {
+			result = append(result, base.String())
+		}
 		if !sliceEqual(result, test.expected) {
 			t.Errorf("解析继承关系 '%s' 失败，期望: %v，实际: %v", test.input, test.expected, result)
 		}
 	}
-}
 
-// TestCppAnalyzer_RemoveComments 测试注释移除功能
-func TestCppAnalyzer_RemoveComments(t *testing.T) {
-	analyzer := NewCppAnalyzer()
+	bases := analyzer.parseInheritance("public virtual ns::Base<int, char>")
+	if len(bases) != 1 || bases[0].Name != "ns::Base" || !sliceEqual(bases[0].TemplateArgs, []string{"int", "char"}) {
+		t.Errorf("基类名和模板实参解析不正确: %+v", bases)
+	}
+}
 
+// TestTokenize_Comments 测试词法分析时的注释移除
+func TestTokenize_Comments(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected string
@@ -143,11 +154,11 @@ func TestCppAnalyzer_RemoveComments(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		inBlockComment := false
-		result := analyzer.removeComments(test.input, &inBlockComment)
-		if strings.TrimSpace(result) != strings.TrimSpace(test.expected) {
+		result := joinTokens(Tokenize(test.input))
+		expected := joinTokens(Tokenize(test.expected))
+		if result != expected || strings.Contains(result, "注释") {
 			t.Errorf("移除注释失败，输入: '%s'，期望: '%s'，实际: '%s'",
-				test.input, test.expected, result)
+				test.input, expected, result)
 		}
 	}
 }
@@ -155,10 +166,10 @@ func TestCppAnalyzer_RemoveComments(t *testing.T) {
 // TestGetInheritanceTree 测试继承树构建
 func TestGetInheritanceTree(t *testing.T) {
 	classes := []*CppClass{
-		{Name: "Animal", BaseClasses: []string{}},
-		{Name: "Dog", BaseClasses: []string{"Animal"}},
-		{Name: "Cat", BaseClasses: []string{"Animal"}},
-		{Name: "WorkingDog", BaseClasses: []string{"Dog"}},
+		{Name: "Animal", BaseClasses: []BaseSpecifier{}},
+		{Name: "Dog", BaseClasses: []BaseSpecifier{{Name: "Animal"}}},
+		{Name: "Cat", BaseClasses: []BaseSpecifier{{Name: "Animal"}}},
+		{Name: "WorkingDog", BaseClasses: []BaseSpecifier{{Name: "Dog"}}},
 	}
 
 	tree := GetInheritanceTree(classes)
@@ -189,10 +200,10 @@ func TestGetInheritanceTree(t *testing.T) {
 // TestFindRootClasses 测试根类查找
 func TestFindRootClasses(t *testing.T) {
 	classes := []*CppClass{
-		{Name: "Animal", BaseClasses: []string{}},
-		{Name: "Vehicle", BaseClasses: []string{}},
-		{Name: "Dog", BaseClasses: []string{"Animal"}},
-		{Name: "Car", BaseClasses: []string{"Vehicle"}},
+		{Name: "Animal", BaseClasses: []BaseSpecifier{}},
+		{Name: "Vehicle", BaseClasses: []BaseSpecifier{}},
+		{Name: "Dog", BaseClasses: []BaseSpecifier{{Name: "Animal"}}},
+		{Name: "Car", BaseClasses: []BaseSpecifier{{Name: "Vehicle"}}},
 	}
 
 	rootClasses := FindRootClasses(classes)
@@ -286,18 +297,30 @@ public:
 		t.Error("未找到Derived类")
 	} else {
 		expectedBases := []string{"Base1", "Base2"}
-		if !sliceEqual(derived.BaseClasses, expectedBases) {
+		if !sliceEqual(baseNames(derived), expectedBases) {
 			t.Errorf("Derived类的基类不正确，期望: %v，实际: %v", expectedBases, derived.BaseClasses)
 		}
 	}
 
+	// 验证虚继承
+	for _, name := range []string{"VirtualDerived1", "VirtualDerived2"} {
+		class := findClassByName(classes, name)
+		if class == nil || len(class.BaseClasses) != 1 {
+			t.Errorf("未找到%s类或基类数量不正确", name)
+			continue
+		}
+		if base := class.BaseClasses[0]; !base.Virtual || base.Access != "public" || base.Name != "VirtualBase" {
+			t.Errorf("%s应该虚继承自VirtualBase，实际: %s", name, base)
+		}
+	}
+
 	// 验证菱形继承
 	diamond := findClassByName(classes, "DiamondInheritance")
 	if diamond == nil {
 		t.Error("未找到DiamondInheritance类")
 	} else {
 		expectedBases := []string{"VirtualDerived1", "VirtualDerived2"}
-		if !sliceEqual(diamond.BaseClasses, expectedBases) {
+		if !sliceEqual(baseNames(diamond), expectedBases) {
 			t.Errorf("DiamondInheritance类的基类不正确，期望: %v，实际: %v", expectedBases, diamond.BaseClasses)
 		}
 	}
@@ -369,7 +392,7 @@ public:
 	if derivedClass, exists := classMap["DerivedClass"]; !exists {
 		t.Error("未找到DerivedClass")
 	} else {
-		if len(derivedClass.BaseClasses) != 1 || derivedClass.BaseClasses[0] != "BaseClass" {
+		if len(derivedClass.BaseClasses) != 1 || derivedClass.BaseClasses[0].Name != "BaseClass" {
 			t.Errorf("DerivedClass应该继承自BaseClass，实际: %v", derivedClass.BaseClasses)
 		}
 		if derivedClass.FilePath == "" {
@@ -424,7 +447,7 @@ public:
 
 	// 验证继承关系解析
 	circleClass := classes[1] // Circle应该是第二个
-	if len(circleClass.BaseClasses) != 1 || circleClass.BaseClasses[0] != "Shape" {
+	if len(circleClass.BaseClasses) != 1 || circleClass.BaseClasses[0].Name != "Shape" {
 		t.Errorf("Circle应该继承自Shape，实际: %v", circleClass.BaseClasses)
 	}
 }
@@ -499,7 +522,7 @@ public:
 	for _, expected := range expectedBases {
 		found := false
 		for _, actual := range multiClass.BaseClasses {
-			if actual == expected {
+			if actual.Name == expected {
 				found = true
 				break
 			}
@@ -510,6 +533,138 @@ public:
 	}
 }
 
+// TestAnalyzeFiles_Namespaces 测试同名类在不同命名空间下的基类查找
+func TestAnalyzeFiles_Namespaces(t *testing.T) {
+	analyzer := NewCppAnalyzer()
+	tempDir := t.TempDir()
+
+	files := map[string]string{
+		"widgets.h": `
+class Widget {};
+namespace ui { class Widget {}; }
+namespace core { class Widget {}; }
+namespace lib { inline namespace v2 { class Base {}; } }
+`,
+		"derived.h": `
+namespace ui {
+    class Button : public Widget {};
+    class Plain : public ::Widget {};
+    class Render : public core::Widget {};
+    namespace dialogs { class Dialog : public Widget {}; }
+}
+namespace core { class Panel : public Widget {}; }
+class Top : public Widget {};
+class Versioned : public lib::Base {};
+class Missing : public ui::Nothing {};
+`,
+	}
+
+	var paths []string
+	for _, name := range []string{"widgets.h", "derived.h"} {
+		path := filepath.Join(tempDir, name)
+		if err := os.WriteFile(path, []byte(files[name]), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+		paths = append(paths, path)
+	}
+
+	classes, err := analyzer.AnalyzeFiles(paths)
+	if err != nil {
+		t.Fatalf("多文件分析失败: %v", err)
+	}
+	if len(classes) != 12 {
+		t.Errorf("期望找到12个类，实际找到%d个", len(classes))
+	}
+
+	expectedBases := map[string][]string{
+		"ui::Button":          {"ui::Widget"},
+		"ui::Plain":           {"Widget"},
+		"ui::Render":          {"core::Widget"},
+		"ui::dialogs::Dialog": {"ui::Widget"},
+		"core::Panel":         {"core::Widget"},
+		"Top":                 {"Widget"},
+		"Versioned":           {"lib::v2::Base"},
+		"Missing":             {"ui::Nothing"},
+	}
+	for _, class := range classes {
+		expected, exists := expectedBases[class.QualifiedName]
+		if !exists {
+			continue
+		}
+		if !sliceEqual(baseNames(class), expected) {
+			t.Errorf("%s 的基类不正确，期望: %v，实际: %v", class.QualifiedName, expected, class.BaseClasses)
+		}
+		for _, baseClass := range class.BaseClasses {
+			if baseClass.External != (class.QualifiedName == "Missing") {
+				t.Errorf("%s 的基类 %s 外部标记不正确: %v", class.QualifiedName, baseClass.Name, baseClass.External)
+			}
+		}
+	}
+
+	// 未找到定义的基类作为外部类节点加入继承树，Missing 不再是根类
+	externals := ExternalClasses(classes, nil)
+	if len(externals) != 1 || externals[0].QualifiedName != "ui::Nothing" || externals[0].Name != "Nothing" ||
+		externals[0].Namespace != "ui" || !externals[0].External {
+		t.Fatalf("外部类不正确: %+v", externals)
+	}
+	all := append(classes, externals...)
+	if children := GetInheritanceTree(all)["ui::Nothing"]; len(children) != 1 || children[0].QualifiedName != "Missing" {
+		t.Errorf("外部类 ui::Nothing 的子类不正确: %v", children)
+	}
+	for _, root := range FindRootClasses(all) {
+		if root.QualifiedName == "Missing" {
+			t.Error("继承自外部类的 Missing 不应是根类")
+		}
+	}
+}
+
+// TestAnalyzeFile_TemplateSpecializations 测试特化与主模板的关联及模板基类的解析
+func TestAnalyzeFile_TemplateSpecializations(t *testing.T) {
+	content := `
+namespace util {
+    template<class T> class Base {};
+    template<> class Base<int> {};
+}
+namespace app {
+    class Derived : public util::Base<Derived> {};
+    class IntDerived : public util::Base<int> {};
+    template<class... Mixins> class Composite : public Mixins... {};
+}
+`
+	tempFile := filepath.Join(t.TempDir(), "templates.h")
+	if err := os.WriteFile(tempFile, []byte(content), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+
+	classes, err := NewCppAnalyzer().AnalyzeFiles([]string{tempFile})
+	if err != nil {
+		t.Fatalf("分析失败: %v", err)
+	}
+
+	classMap := make(map[string]*CppClass)
+	for _, class := range classes {
+		classMap[class.QualifiedName] = class
+	}
+
+	if spec := classMap["util::Base<int>"]; spec == nil || spec.PrimaryTemplate != "util::Base" {
+		t.Errorf("util::Base<int> 应关联到主模板 util::Base: %+v", spec)
+	}
+	if derived := classMap["app::Derived"]; derived == nil || !sliceEqual(baseNames(derived), []string{"util::Base<Derived>"}) {
+		t.Errorf("app::Derived 的基类不正确: %+v", derived)
+	}
+	if intDerived := classMap["app::IntDerived"]; intDerived == nil || !sliceEqual(baseNames(intDerived), []string{"util::Base<int>"}) {
+		t.Errorf("app::IntDerived 的基类不正确: %+v", intDerived)
+	}
+	if composite := classMap["app::Composite"]; composite == nil || !sliceEqual(baseNames(composite), []string{"Mixins..."}) {
+		t.Errorf("形参包展开基类不应被移除: %+v", composite)
+	}
+
+	tree := GetInheritanceTree(classes)
+	if len(tree["util::Base"]) != 1 || len(tree["util::Base<int>"]) != 1 {
+		t.Errorf("继承树中的模板基类分组不正确: %v", tree)
+	}
+}
+
 // 辅助函数：根据名称查找类
 func findClassByName(classes []*CppClass, name string) *CppClass {
 	for _, class := range classes {
@@ -520,6 +675,19 @@ func findClassByName(classes []*CppClass, name string) *CppClass {
 	return nil
 }
 
+// 辅助函数：返回类的基类名列表(带模板实参和形参包展开)
+func baseNames(class *CppClass) []string {
+	var names []string
+	for _, base := range class.BaseClasses {
+		name := base.FullName()
+		if base.Variadic {
+			name += "..."
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
 // 辅助函数：比较两个字符串切片是否相等
 func sliceEqual(a, b []string) bool {
 	if len(a) != len(b) {
@@ -532,3 +700,17 @@ func sliceEqual(a, b []string) bool {
 	}
 	return true
 }
+
+// 辅助方法：对源代码做词法分析和预处理，并提取活动分支中的类定义
+func (a *CppAnalyzer) analyzeSource(src string) []*CppClass {
+	result := parseTokens(Tokenize(src), a.macros)
+	for ns := range result.inlineNamespaces {
+		a.inlineNamespaces[ns] = true
+	}
+	return result.classes
+}
+
+// 辅助方法：解析继承关系字符串，未写明继承方式时按 class 的默认方式 private 处理
+func (a *CppAnalyzer) parseInheritance(inheritanceStr string) []BaseSpecifier {
+	return parseBaseClause(Tokenize(inheritanceStr), "private")
+}
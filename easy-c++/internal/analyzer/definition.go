@@ -0,0 +1,75 @@
+package analyzer
+
+// MethodDefinition 表示在类体外定义的成员函数，如 void Circle::draw() const { ... }
+type MethodDefinition struct {
+	Class    string // 定义中的类名限定，如 Circle、geo::Circle、Stack<T>
+	Scope    string // 定义所在的命名空间，类名从这里出发查找
+	Method   Method // 按定义解析得到的方法，LineNumber 为定义所在行号
+	FilePath string // 定义所在的文件路径
+	Column   int    // 定义中函数名开始的列号
+}
+
+// linkMethodDefinitions 把类体外的成员函数定义关联到类中的声明，记录每个方法的定义位置
+// 类中找不到对应声明的定义记入 UndeclaredMethods；定义的类不在分析范围内时忽略。
+// 只分析头文件时找不到定义是正常的，因此只在至少关联到一个类体外定义的类中把没有定义的方法标记为 Undefined
+func (a *CppAnalyzer) linkMethodDefinitions(classes []*CppClass) {
+	index := newClassIndex(classes, a.inlineNamespaces, nil)
+	linked := make(map[*CppClass]bool)
+	for _, def := range a.definitions {
+		class := index.lookup(def.Class, def.Scope, nil)
+		if class == nil {
+			// 类模板的成员，如 Stack<T>::push
+			class = index.lookup(TemplateName(def.Class), def.Scope, nil)
+		}
+		if class == nil {
+			continue
+		}
+		linked[class] = true
+
+		method := findDeclaration(class, def.Method)
+		if method == nil {
+			undeclared := def.Method
+			undeclared.DefinitionFile, undeclared.DefinitionLine = def.FilePath, def.Method.LineNumber
+			class.UndeclaredMethods = append(class.UndeclaredMethods, undeclared)
+			a.report(SeverityWarning, CodeUndeclaredDefinition, def.FilePath, def.Method.LineNumber, def.Column,
+				"类 %s 中没有声明 %s", class.QualifiedName, def.Method.Signature())
+			continue
+		}
+		method.DefinitionFile, method.DefinitionLine = def.FilePath, def.Method.LineNumber
+	}
+
+	for _, class := range classes {
+		for i := range class.Methods {
+			method := &class.Methods[i]
+			if method.DefinitionLine > 0 && method.DefinitionFile == "" {
+				// 在类体内定义
+				method.DefinitionFile = class.FilePath
+			}
+			method.Undefined = linked[class] && method.DefinitionLine == 0 &&
+				!method.Pure && !method.Defaulted && !method.Deleted
+		}
+	}
+}
+
+// findDeclaration 在类中查找与定义对应的声明: 先按签名精确匹配，
+// 再在尚未关联定义的方法中找方法名、形参个数和 const 限定都相同的唯一一个(形参类型的写法可能不同，如 string 与 std::string)
+func findDeclaration(class *CppClass, def Method) *Method {
+	signature := def.Signature()
+	for i := range class.Methods {
+		if class.Methods[i].Signature() == signature {
+			return &class.Methods[i]
+		}
+	}
+	var candidate *Method
+	for i := range class.Methods {
+		method := &class.Methods[i]
+		if method.Name != def.Name || len(method.Params) != len(def.Params) || method.Const != def.Const || method.DefinitionLine > 0 {
+			continue
+		}
+		if candidate != nil {
+			return nil
+		}
+		candidate = method
+	}
+	return candidate
+}
@@ -0,0 +1,131 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestLinkMethodDefinitions 测试类体外的成员函数定义与类中声明的关联
+func TestLinkMethodDefinitions(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"shape.h": `#pragma once
+namespace geo {
+class Shape {
+public:
+    virtual ~Shape();
+    virtual void draw() const = 0;
+    Shape& operator=(const Shape&) = default;
+};
+template <typename T>
+class Stack {
+public:
+    void push(const T& v);
+    T pop() { return T(); }
+};
+class Circle : public Shape {
+public:
+    Circle(double r);
+    ~Circle() override;
+    void draw() const override;
+    bool operator==(const Circle& o) const;
+    void setName(const std::string& name);
+    void missing();
+private:
+    double r_;
+};
+}
+class HeaderOnly {
+    void declared();
+};
+`,
+		"shape.cpp": `#include "shape.h"
+namespace geo {
+Shape::~Shape() {}
+Circle::Circle(double r) : Shape(), r_(r) {}
+Circle::~Circle() noexcept {}
+void Circle::draw() const {
+    if (r_ > 0) { }
+}
+bool Circle::operator==(const Circle& o) const { return r_ == o.r_; }
+}
+void geo::Circle::setName(const string& name) {}
+void geo::Circle::extra(int) {}
+template <typename T>
+void geo::Stack<T>::push(const T& v) {}
+void Unknown::method() {}
+int freeFunction() { return 0; }
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+	}
+	header, source := filepath.Join(tempDir, "shape.h"), filepath.Join(tempDir, "shape.cpp")
+
+	classes, err := NewCppAnalyzer().AnalyzeFiles([]string{source})
+	if err != nil {
+		t.Fatalf("多文件分析失败: %v", err)
+	}
+
+	type location struct {
+		file string
+		line int
+	}
+	expected := map[string]map[string]location{
+		"Shape": {
+			"~Shape()":                {source, 3},
+			"draw() const":            {"", 0},
+			"operator=(const Shape&)": {"", 0},
+		},
+		"Stack": {
+			"push(const T&)": {source, 14},
+			"pop()":          {header, 13},
+		},
+		"Circle": {
+			"Circle(double)":                  {source, 4},
+			"~Circle()":                       {source, 5},
+			"draw() const":                    {source, 6},
+			"operator==(const Circle&) const": {source, 9},
+			"setName(const std::string&)":     {source, 11},
+			"missing()":                       {"", 0},
+		},
+		"HeaderOnly": {
+			"declared()": {"", 0},
+		},
+	}
+	for className, methods := range expected {
+		class := findClassByName(classes, className)
+		if class == nil {
+			t.Errorf("未找到类 %s", className)
+			continue
+		}
+		for _, method := range class.Methods {
+			want, ok := methods[method.Signature()]
+			if !ok {
+				t.Errorf("%s 中有意外的方法 %s", className, method.Signature())
+				continue
+			}
+			if method.DefinitionFile != want.file || method.DefinitionLine != want.line {
+				t.Errorf("%s::%s 的定义位置应为 %s:%d，实际: %s:%d", className, method.Signature(),
+					want.file, want.line, method.DefinitionFile, method.DefinitionLine)
+			}
+		}
+	}
+
+	// 只有 Circle::missing 是声明了但没有定义的方法: 纯虚函数和 = default 不需要定义，HeaderOnly 没有任何类体外定义
+	for _, class := range classes {
+		for _, method := range class.Methods {
+			if method.Undefined != (class.Name == "Circle" && method.Name == "missing") {
+				t.Errorf("%s::%s 的 Undefined 应为 %v", class.Name, method.Signature(), !method.Undefined)
+			}
+		}
+	}
+	circle := findClassByName(classes, "Circle")
+	if circle == nil || len(circle.UndeclaredMethods) != 1 || circle.UndeclaredMethods[0].Signature() != "extra(int)" ||
+		circle.UndeclaredMethods[0].DefinitionLine != 12 {
+		t.Errorf("Circle 应有1个未声明的定义 extra(int)，实际: %+v", circle)
+	}
+}
@@ -0,0 +1,88 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity 诊断的严重程度
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // 文件未能分析，结果不完整
+	SeverityWarning Severity = "warning" // 代码中可能有问题，如同名类的定义不一致
+	SeverityNote    Severity = "note"    // 补充说明，如先前定义的位置
+)
+
+// 诊断代码
+const (
+	CodeFileRead              = "file-read"              // 无法读取文件
+	CodeErrorDirective        = "error-directive"        // 活动分支中的 #error 或 #warning
+	CodeUnbalancedConditional = "unbalanced-conditional" // #if 与 #endif 不配对
+	CodeODRConflict           = "odr-conflict"           // 同名类在多处有不同的定义
+	CodeDuplicateDefinition   = "duplicate-definition"   // 同名类在多处有相同的定义，已合并
+	CodeUndeclaredDefinition  = "undeclared-definition"  // 类体外定义的成员函数在类中没有声明
+	CodeSymlinkLoop           = "symlink-loop"           // 遍历项目目录时遇到形成循环的符号链接
+	CodeInvalidEncoding       = "invalid-encoding"       // 无法识别文件的编码
+	CodeInvalidDirective      = "invalid-directive"      // 无法解析的预处理指令，如形参表缺少右括号的 #define 和无法计算的 #if 条件
+)
+
+// Diagnostic 分析过程中发现的一个问题
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`             // 诊断代码，如 odr-conflict
+	Message  string   `json:"message"`          // 问题描述
+	File     string   `json:"file,omitempty"`   // 所在文件，与具体文件无关时为空
+	Line     int      `json:"line,omitempty"`   // 所在行号(从1开始)，未知时为0
+	Column   int      `json:"column,omitempty"` // 所在列号(从1开始)，未知时为0
+}
+
+// String 返回编译器风格的诊断，如 shape.h:3:7: warning: ... [odr-conflict]
+func (d Diagnostic) String() string {
+	var location []string
+	if d.File != "" {
+		location = append(location, d.File)
+		if d.Line > 0 {
+			location = append(location, fmt.Sprint(d.Line))
+			if d.Column > 0 {
+				location = append(location, fmt.Sprint(d.Column))
+			}
+		}
+	}
+	s := fmt.Sprintf("%s: %s [%s]", d.Severity, d.Message, d.Code)
+	if len(location) > 0 {
+		s = strings.Join(location, ":") + ": " + s
+	}
+	return s
+}
+
+// Diagnostics 诊断列表，按发现的顺序排列
+type Diagnostics []Diagnostic
+
+// Count 返回指定严重程度的诊断个数
+func (ds Diagnostics) Count(severity Severity) int {
+	n := 0
+	for _, d := range ds {
+		if d.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// Diagnostics 返回最近一次分析产生的诊断
+func (a *CppAnalyzer) Diagnostics() Diagnostics {
+	return a.diagnostics
+}
+
+// report 记录一条诊断
+func (a *CppAnalyzer) report(severity Severity, code, file string, line, column int, format string, args ...any) {
+	a.diagnostics = append(a.diagnostics, Diagnostic{
+		Severity: severity,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+		File:     file,
+		Line:     line,
+		Column:   column,
+	})
+}
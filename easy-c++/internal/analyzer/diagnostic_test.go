@@ -0,0 +1,101 @@
+package analyzer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestDiagnostics 测试分析过程中产生的诊断
+func TestDiagnostics(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"a.h": `#ifdef LEGACY
+#error legacy build
+#endif
+#warning deprecated header
+class Widget {
+    void draw();
+};
+#if 1
+`,
+		"b.h": `#endif
+class Widget {
+    void draw();
+    int size;
+};
+`,
+		"c.h": `#if VERSION >=
+class Broken {};
+#elif (1 + 2
+class Unclosed {};
+#endif
+`,
+		"widget.cpp": `void Widget::resize(int w) {}
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+	}
+	// 无法读取的文件
+	if err := os.Symlink(filepath.Join(tempDir, "missing.h"), filepath.Join(tempDir, "broken.h")); err != nil {
+		t.Skipf("无法创建符号链接: %v", err)
+	}
+
+	a := NewCppAnalyzer()
+	var failed *AnalysisError
+	if _, err := a.AnalyzeProject(tempDir); !errors.As(err, &failed) || len(failed.Files) != 1 {
+		t.Fatalf("应报告1个分析失败的文件，实际: %v", err)
+	}
+
+	type key struct {
+		code string
+		file string
+		line int
+	}
+	found := make(map[key]Diagnostic)
+	for _, d := range a.Diagnostics() {
+		found[key{d.Code, filepath.Base(d.File), d.Line}] = d
+	}
+	expected := map[key]Severity{
+		{CodeFileRead, "broken.h", 0}:               SeverityError,
+		{CodeErrorDirective, "a.h", 4}:              SeverityWarning,
+		{CodeUnbalancedConditional, "a.h", 8}:       SeverityWarning,
+		{CodeUnbalancedConditional, "b.h", 1}:       SeverityWarning,
+		{CodeODRConflict, "b.h", 2}:                 SeverityWarning,
+		{CodeUndeclaredDefinition, "widget.cpp", 1}: SeverityWarning,
+		{CodeInvalidDirective, "c.h", 1}:            SeverityWarning,
+		{CodeInvalidDirective, "c.h", 3}:            SeverityWarning,
+	}
+	if len(found) != len(expected) {
+		t.Errorf("期望 %d 条诊断，实际: %v", len(expected), a.Diagnostics())
+	}
+	for k, severity := range expected {
+		if d, ok := found[k]; !ok || d.Severity != severity {
+			t.Errorf("未找到诊断 %+v (%s)，实际: %v", k, severity, a.Diagnostics())
+		}
+	}
+	if n := a.Diagnostics().Count(SeverityWarning); n != 7 {
+		t.Errorf("期望 7 个警告，实际: %d", n)
+	}
+
+	d := Diagnostic{Severity: SeverityWarning, Code: CodeODRConflict, Message: "类 Widget 的定义不一致", File: "b.h", Line: 2, Column: 1}
+	if s := d.String(); s != "b.h:2:1: warning: 类 Widget 的定义不一致 [odr-conflict]" {
+		t.Errorf("诊断的文本形式不正确: %s", s)
+	}
+	d.File, d.Line, d.Column = "", 0, 0
+	if s := d.String(); s != "warning: 类 Widget 的定义不一致 [odr-conflict]" {
+		t.Errorf("没有位置的诊断的文本形式不正确: %s", s)
+	}
+
+	// 新的分析清除上一次的诊断
+	if _, err := a.AnalyzeFiles([]string{filepath.Join(tempDir, "widget.cpp")}); err != nil {
+		t.Fatalf("多文件分析失败: %v", err)
+	}
+	if len(a.Diagnostics()) != 0 {
+		t.Errorf("诊断未被清除: %v", a.Diagnostics())
+	}
+}
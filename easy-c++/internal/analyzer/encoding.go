@@ -0,0 +1,226 @@
+package analyzer
+
+import (
+	_ "embed"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// 源文件的编码
+const (
+	encodingUTF8    = "UTF-8"
+	encodingUTF16LE = "UTF-16LE"
+	encodingUTF16BE = "UTF-16BE"
+	encodingGB18030 = "GB18030"
+)
+
+// decodeSource 把源文件的内容解码为 UTF-8 文本，并去掉开头的 BOM
+// 依次识别: UTF-8 BOM；UTF-16 BOM，或没有 BOM 但大量字节为 0 的 UTF-16 文本；有效的 UTF-8；
+// 能完整解码的 GB18030(兼容 GBK 和 GB2312)。都不符合时原样返回，ok 为 false
+func decodeSource(content []byte) (text, encoding string, ok bool) {
+	switch {
+	case len(content) >= 3 && content[0] == 0xEF && content[1] == 0xBB && content[2] == 0xBF:
+		return string(content[3:]), encodingUTF8, utf8.Valid(content[3:])
+	case len(content) >= 2 && content[0] == 0xFF && content[1] == 0xFE:
+		return decodeUTF16(content[2:], binary.LittleEndian), encodingUTF16LE, true
+	case len(content) >= 2 && content[0] == 0xFE && content[1] == 0xFF:
+		return decodeUTF16(content[2:], binary.BigEndian), encodingUTF16BE, true
+	}
+	if order := utf16Order(content); order != nil {
+		encoding := encodingUTF16LE
+		if order == binary.ByteOrder(binary.BigEndian) {
+			encoding = encodingUTF16BE
+		}
+		return decodeUTF16(content, order), encoding, true
+	}
+	if utf8.Valid(content) {
+		return string(content), encodingUTF8, true
+	}
+	if text, ok := decodeGB18030(content); ok {
+		return text, encodingGB18030, true
+	}
+	return string(content), "", false
+}
+
+// utf16Order 判断没有 BOM 的内容是否为 UTF-16 文本并返回字节序，不是时返回 nil
+// C++ 源代码以 ASCII 字符为主，UTF-16 编码时大多数字符的高字节为 0
+func utf16Order(content []byte) binary.ByteOrder {
+	n := min(len(content), sniffSize) &^ 1
+	if n < 4 {
+		return nil
+	}
+	var evenZeros, oddZeros int
+	for i := 0; i < n; i += 2 {
+		if content[i] == 0 {
+			evenZeros++
+		}
+		if content[i+1] == 0 {
+			oddZeros++
+		}
+	}
+	units := n / 2
+	switch {
+	case oddZeros*2 > units && evenZeros*10 < units:
+		return binary.LittleEndian
+	case evenZeros*2 > units && oddZeros*10 < units:
+		return binary.BigEndian
+	}
+	return nil
+}
+
+// decodeUTF16 解码 UTF-16 文本，末尾多余的单个字节被忽略
+func decodeUTF16(content []byte, order binary.ByteOrder) string {
+	units := make([]uint16, len(content)/2)
+	for i := range units {
+		units[i] = order.Uint16(content[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
+
+//go:embed tables/gb18030.txt
+var gb18030Source string
+
+// gb18030Table 返回 GB18030 双字节编码对应的 Unicode 码点，按 (首字节-0x81)*190 + 尾字节序号 排列，
+// 尾字节 0x40-0x7E 和 0x80-0xFE 依次编号。第一次解码 GB18030 文件时从 tables/gb18030.txt 生成
+var gb18030Table = sync.OnceValue(func() []uint16 {
+	table, err := parseGB18030Table(gb18030Source)
+	if err != nil {
+		// 内置的映射表由测试检查，不会出错
+		panic(err)
+	}
+	return table
+})
+
+// parseGB18030Table 解析 tables/gb18030.txt，检查其中按顺序列出了全部双字节编码
+func parseGB18030Table(content string) ([]uint16, error) {
+	table := make([]uint16, 0, 126*190)
+	for n, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var code, r uint16
+		if _, err := fmt.Sscanf(line, "%04X %04X", &code, &r); err != nil {
+			return nil, fmt.Errorf("GB18030 映射表第 %d 行格式错误: %v", n+1, err)
+		}
+		index := len(table)
+		trail := index%190 + 0x40
+		if trail >= 0x7F {
+			trail++
+		}
+		if expected := uint16(0x81+index/190)<<8 | uint16(trail); code != expected {
+			return nil, fmt.Errorf("GB18030 映射表第 %d 行: 期望编码 %04X，实际为 %04X", n+1, expected, code)
+		}
+		table = append(table, r)
+	}
+	if len(table) != cap(table) {
+		return nil, fmt.Errorf("GB18030 映射表应有 %d 个编码，实际为 %d 个", cap(table), len(table))
+	}
+	return table, nil
+}
+
+// gb18030Range GB18030 四字节编码中映射到连续码点的一段: 从序号 index 开始依次对应从 rune 开始的码点
+type gb18030Range struct {
+	index int
+	rune  rune
+}
+
+// gb18030Ranges 四字节编码 0x81308130-0x8431A439 (序号 0-39419) 到基本多文种平面的映射
+var gb18030Ranges = []gb18030Range{
+	{0, 0x0080}, {36, 0x00A5}, {38, 0x00A9}, {45, 0x00B2}, {50, 0x00B8}, {81, 0x00D8},
+	{89, 0x00E2}, {95, 0x00EB}, {96, 0x00EE}, {100, 0x00F4}, {103, 0x00F8}, {104, 0x00FB},
+	{105, 0x00FD}, {109, 0x0102}, {126, 0x0114}, {133, 0x011C}, {148, 0x012C}, {172, 0x0145},
+	{175, 0x0149}, {179, 0x014E}, {208, 0x016C}, {306, 0x01CF}, {307, 0x01D1}, {308, 0x01D3},
+	{309, 0x01D5}, {310, 0x01D7}, {311, 0x01D9}, {312, 0x01DB}, {313, 0x01DD}, {341, 0x01FA},
+	{428, 0x0252}, {443, 0x0262}, {544, 0x02C8}, {545, 0x02CC}, {558, 0x02DA}, {741, 0x03A2},
+	{742, 0x03AA}, {749, 0x03C2}, {750, 0x03CA}, {805, 0x0402}, {819, 0x0450}, {820, 0x0452},
+	{7922, 0x2011}, {7924, 0x2017}, {7925, 0x201A}, {7927, 0x201E}, {7934, 0x2027}, {7943, 0x2031},
+	{7944, 0x2034}, {7945, 0x2036}, {7950, 0x203C}, {8062, 0x20AD}, {8148, 0x2104}, {8149, 0x2106},
+	{8152, 0x210A}, {8164, 0x2117}, {8174, 0x2122}, {8236, 0x216C}, {8240, 0x217A}, {8262, 0x2194},
+	{8264, 0x219A}, {8374, 0x2209}, {8380, 0x2210}, {8381, 0x2212}, {8384, 0x2216}, {8388, 0x221B},
+	{8390, 0x2221}, {8392, 0x2224}, {8393, 0x2226}, {8394, 0x222C}, {8396, 0x222F}, {8401, 0x2238},
+	{8406, 0x223E}, {8416, 0x2249}, {8419, 0x224D}, {8424, 0x2253}, {8437, 0x2262}, {8439, 0x2268},
+	{8445, 0x2270}, {8482, 0x2296}, {8485, 0x229A}, {8496, 0x22A6}, {8521, 0x22C0}, {8603, 0x2313},
+	{8936, 0x246A}, {8946, 0x249C}, {9046, 0x254C}, {9050, 0x2574}, {9063, 0x2590}, {9066, 0x2596},
+	{9076, 0x25A2}, {9092, 0x25B4}, {9100, 0x25BE}, {9108, 0x25C8}, {9111, 0x25CC}, {9113, 0x25D0},
+	{9131, 0x25E6}, {9162, 0x2607}, {9164, 0x260A}, {9218, 0x2641}, {9219, 0x2643}, {11329, 0x2E82},
+	{11331, 0x2E85}, {11334, 0x2E89}, {11336, 0x2E8D}, {11346, 0x2E98}, {11361, 0x2EA8}, {11363, 0x2EAB},
+	{11366, 0x2EAF}, {11370, 0x2EB4}, {11372, 0x2EB8}, {11375, 0x2EBC}, {11389, 0x2ECB}, {11682, 0x2FFC},
+	{11686, 0x3004}, {11687, 0x3018}, {11692, 0x301F}, {11694, 0x302A}, {11714, 0x303F}, {11716, 0x3094},
+	{11723, 0x309F}, {11725, 0x30F7}, {11730, 0x30FF}, {11736, 0x312A}, {11982, 0x322A}, {11989, 0x3232},
+	{12102, 0x32A4}, {12336, 0x3390}, {12348, 0x339F}, {12350, 0x33A2}, {12384, 0x33C5}, {12393, 0x33CF},
+	{12395, 0x33D3}, {12397, 0x33D6}, {12510, 0x3448}, {12553, 0x3474}, {12851, 0x359F}, {12962, 0x360F},
+	{12973, 0x361B}, {13738, 0x3919}, {13823, 0x396F}, {13919, 0x39D1}, {13933, 0x39E0}, {14080, 0x3A74},
+	{14298, 0x3B4F}, {14585, 0x3C6F}, {14698, 0x3CE1}, {15583, 0x4057}, {15847, 0x4160}, {16318, 0x4338},
+	{16434, 0x43AD}, {16438, 0x43B2}, {16481, 0x43DE}, {16729, 0x44D7}, {17102, 0x464D}, {17122, 0x4662},
+	{17315, 0x4724}, {17320, 0x472A}, {17402, 0x477D}, {17418, 0x478E}, {17859, 0x4948}, {17909, 0x497B},
+	{17911, 0x497E}, {17915, 0x4984}, {17916, 0x4987}, {17936, 0x499C}, {17939, 0x49A0}, {17961, 0x49B8},
+	{18664, 0x4C78}, {18703, 0x4CA4}, {18814, 0x4D1A}, {18962, 0x4DAF}, {19043, 0x9FA6}, {33469, 0xE76C},
+	{33470, 0xE7C8}, {33471, 0xE7E7}, {33484, 0xE815}, {33485, 0xE819}, {33490, 0xE81F}, {33497, 0xE827},
+	{33501, 0xE82D}, {33505, 0xE833}, {33513, 0xE83C}, {33520, 0xE844}, {33536, 0xE856}, {33550, 0xE865},
+	{37845, 0xF92D}, {37921, 0xF97A}, {37948, 0xF996}, {38029, 0xF9E8}, {38038, 0xF9F2}, {38064, 0xFA10},
+	{38065, 0xFA12}, {38066, 0xFA15}, {38069, 0xFA19}, {38075, 0xFA22}, {38076, 0xFA25}, {38078, 0xFA2A},
+	{39108, 0xFE32}, {39109, 0xFE45}, {39113, 0xFE53}, {39114, 0xFE58}, {39115, 0xFE67}, {39116, 0xFE6C},
+	{39265, 0xFF5F}, {39394, 0xFFE6},
+}
+
+// decodeGB18030 按 GB18030 解码，遇到无效的字节序列时 ok 为 false
+func decodeGB18030(content []byte) (string, bool) {
+	var sb strings.Builder
+	sb.Grow(len(content) + len(content)/2)
+	for i := 0; i < len(content); {
+		b := content[i]
+		switch {
+		case b < 0x80:
+			sb.WriteByte(b)
+			i++
+		case b == 0x80 || b == 0xFF || i+1 >= len(content):
+			return "", false
+		case content[i+1] >= 0x30 && content[i+1] <= 0x39:
+			// 四字节编码
+			if i+3 >= len(content) || content[i+2] < 0x81 || content[i+2] > 0xFE || content[i+3] < 0x30 || content[i+3] > 0x39 {
+				return "", false
+			}
+			index := ((int(b-0x81)*10+int(content[i+1]-0x30))*126+int(content[i+2]-0x81))*10 + int(content[i+3]-0x30)
+			r, ok := gb18030FourByte(index)
+			if !ok {
+				return "", false
+			}
+			sb.WriteRune(r)
+			i += 4
+		default:
+			trail := content[i+1]
+			if trail < 0x40 || trail == 0x7F || trail == 0xFF {
+				return "", false
+			}
+			offset := int(trail - 0x40)
+			if trail > 0x7F {
+				offset--
+			}
+			index := int(b-0x81)*190 + offset
+			sb.WriteRune(rune(gb18030Table()[index]))
+			i += 2
+		}
+	}
+	return sb.String(), true
+}
+
+// gb18030FourByte 返回四字节编码序号对应的码点
+func gb18030FourByte(index int) (rune, bool) {
+	// 0x90308130 起为辅助平面，依次对应 U+10000-U+10FFFF
+	const supplementary = (0x90 - 0x81) * 10 * 126 * 10
+	switch {
+	case index >= supplementary:
+		r := rune(index-supplementary) + 0x10000
+		return r, r <= utf8.MaxRune
+	case index > 39419:
+		return 0, false
+	}
+	i := sort.Search(len(gb18030Ranges), func(i int) bool { return gb18030Ranges[i].index > index }) - 1
+	return gb18030Ranges[i].rune + rune(index-gb18030Ranges[i].index), true
+}
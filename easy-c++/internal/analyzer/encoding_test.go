@@ -0,0 +1,122 @@
+package analyzer
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// encodeUTF16 把文本编码为 UTF-16，bom 表示是否在开头加上 BOM
+func encodeUTF16(s string, order binary.AppendByteOrder, bom bool) []byte {
+	var out []byte
+	units := utf16.Encode([]rune(s))
+	if bom {
+		units = append([]uint16{0xFEFF}, units...)
+	}
+	for _, u := range units {
+		out = order.AppendUint16(out, u)
+	}
+	return out
+}
+
+// TestDecodeSource 测试源文件编码的识别和解码
+func TestDecodeSource(t *testing.T) {
+	const text = "// 形状基类 ä 😀\nclass Shape {};\n"
+	gb18030 := []byte("// \xd0\xce\xd7\xb4\xbb\xf9\xc0\xe0 \x81\x30\x8a\x31 \x94\x39\xfc\x36\nclass Shape {};\n")
+	tests := []struct {
+		name     string
+		content  []byte
+		encoding string
+	}{
+		{"UTF-8", []byte(text), encodingUTF8},
+		{"UTF-8 BOM", append([]byte("\xef\xbb\xbf"), text...), encodingUTF8},
+		{"UTF-16LE BOM", encodeUTF16(text, binary.LittleEndian, true), encodingUTF16LE},
+		{"UTF-16BE BOM", encodeUTF16(text, binary.BigEndian, true), encodingUTF16BE},
+		{"UTF-16LE", encodeUTF16(text, binary.LittleEndian, false), encodingUTF16LE},
+		{"UTF-16BE", encodeUTF16(text, binary.BigEndian, false), encodingUTF16BE},
+		{"GB18030", gb18030, encodingGB18030},
+	}
+	for _, tt := range tests {
+		decoded, encoding, ok := decodeSource(tt.content)
+		if !ok || encoding != tt.encoding || decoded != text {
+			t.Errorf("%s: 解码结果不正确: %q (%s, %v)", tt.name, decoded, encoding, ok)
+		}
+	}
+
+	if _, _, ok := decodeSource([]byte("class A {}; // \x80\xff\n")); ok {
+		t.Error("无效的字节序列不应解码成功")
+	}
+}
+
+// TestSourceEncodings 测试分析不同编码、超长行和不同换行符的文件
+func TestSourceEncodings(t *testing.T) {
+	tempDir := t.TempDir()
+	src := "/* 中文注释 */ #define EXPORT\nclass Base {};\n/* 形状 */ class EXPORT Derived : public Base {};\n"
+	longLine := "static const int table[] = {" + strings.Repeat("0, ", 30000) + "};\n"
+	files := map[string][]byte{
+		"lf.h":    []byte(src),
+		"crlf.h":  []byte(strings.ReplaceAll(src, "\n", "\r\n")),
+		"cr.h":    []byte(strings.ReplaceAll(src, "\n", "\r")),
+		"bom.h":   append([]byte("\xef\xbb\xbf"), src...),
+		"utf16.h": encodeUTF16(src, binary.LittleEndian, true),
+		"gbk.h":   []byte("/* \xd6\xd0\xce\xc4\xd7\xa2\xca\xcd */ #define EXPORT\nclass Base {};\n/* \xd0\xce\xd7\xb4 */ class EXPORT Derived : public Base {};\n"),
+		"long.h":  []byte(longLine + src),
+		"bad.h":   []byte("class Bad {}; // \x80\n"),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), content, 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+	}
+
+	type position struct {
+		name         string
+		line, column int
+		bases        int
+	}
+	analyze := func(name string) ([]position, Diagnostics) {
+		a := NewCppAnalyzer()
+		classes, err := a.AnalyzeFile(filepath.Join(tempDir, name))
+		if err != nil {
+			t.Fatalf("%s: 分析失败: %v", name, err)
+		}
+		var positions []position
+		for _, class := range classes {
+			positions = append(positions, position{class.Name, class.LineNumber, class.Column, len(class.BaseClasses)})
+		}
+		return positions, a.Diagnostics()
+	}
+
+	// 列号按字符计算: "/* 形状 */ " 占 9 列
+	expected := []position{{"Base", 2, 1, 0}, {"Derived", 3, 10, 1}}
+	for _, name := range []string{"lf.h", "crlf.h", "cr.h", "bom.h", "utf16.h", "gbk.h"} {
+		if positions, diagnostics := analyze(name); !reflect.DeepEqual(positions, expected) || len(diagnostics) != 0 {
+			t.Errorf("%s: 类的位置不正确: %v %v", name, positions, diagnostics)
+		}
+	}
+	if positions, _ := analyze("long.h"); len(positions) != 2 || positions[1].line != 4 {
+		t.Errorf("超长行之后的类不正确: %v", positions)
+	}
+	if positions, diagnostics := analyze("bad.h"); len(positions) != 1 ||
+		len(diagnostics) != 1 || diagnostics[0].Code != CodeInvalidEncoding || filepath.Base(diagnostics[0].File) != "bad.h" {
+		t.Errorf("无法识别编码的文件应按原样分析并报告警告: %v %v", positions, diagnostics)
+	}
+}
+
+// TestGB18030Table 测试内置的 GB18030 映射表完整且按编码排列
+func TestGB18030Table(t *testing.T) {
+	table := gb18030Table()
+	if len(table) != 23940 || table[0] != 0x4E02 || table[(0xB0-0x81)*190+(0xA1-0x41)] != '啊' {
+		t.Errorf("映射表内容不正确: %d 项", len(table))
+	}
+	if _, err := parseGB18030Table("8140 4E02\n8142 4E05\n"); err == nil || !strings.Contains(err.Error(), "期望编码 8141") {
+		t.Errorf("缺少编码的映射表应报错，实际: %v", err)
+	}
+	if _, err := parseGB18030Table("8140 4E02\n"); err == nil {
+		t.Errorf("不完整的映射表应报错")
+	}
+}
@@ -0,0 +1,65 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FileError 表示一个文件分析失败
+type FileError struct {
+	Path string // 文件路径
+	Err  error  // 失败的原因
+}
+
+// Error 返回失败的文件和原因
+func (e *FileError) Error() string {
+	return fmt.Sprintf("分析文件 %s 时出错: %v", e.Path, e.Err)
+}
+
+// Unwrap 返回失败的原因
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// AnalysisError 汇总一次分析中失败的所有文件
+// 分析函数返回 AnalysisError 时，同时返回其余文件的分析结果
+type AnalysisError struct {
+	Files []*FileError // 失败的文件，按分析顺序排列
+}
+
+// Error 列出所有失败的文件
+func (e *AnalysisError) Error() string {
+	if len(e.Files) == 1 {
+		return e.Files[0].Error()
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d 个文件分析失败:", len(e.Files))
+	for _, file := range e.Files {
+		sb.WriteString("\n  " + file.Error())
+	}
+	return sb.String()
+}
+
+// Unwrap 返回各个文件的错误，可用 errors.As 取出 *FileError
+func (e *AnalysisError) Unwrap() []error {
+	errs := make([]error, len(e.Files))
+	for i, file := range e.Files {
+		errs[i] = file
+	}
+	return errs
+}
+
+// SetFailFast 设置遇到分析失败的文件时是否立即停止
+// 默认继续分析其余文件，最后以 AnalysisError 汇总所有失败的文件；
+// 立即停止时 AnalysisError 中只有第一个失败的文件，结果只包含在它之前分析的文件
+func (a *CppAnalyzer) SetFailFast(failFast bool) {
+	a.failFast = failFast
+}
+
+// analysisError 把失败的文件汇总为 AnalysisError，没有失败的文件时返回 nil
+func analysisError(failed []*FileError) error {
+	if len(failed) == 0 {
+		return nil
+	}
+	return &AnalysisError{Files: failed}
+}
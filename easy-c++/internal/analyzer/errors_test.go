@@ -0,0 +1,60 @@
+package analyzer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestAnalysisError 测试部分文件分析失败时返回其余文件的结果和汇总的错误
+func TestAnalysisError(t *testing.T) {
+	tempDir := t.TempDir()
+	for name, content := range map[string]string{
+		"base.h":    "class Base {};\n",
+		"derived.h": "class Derived : public Base {};\n",
+	} {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+	}
+	paths := []string{
+		filepath.Join(tempDir, "base.h"),
+		filepath.Join(tempDir, "missing1.h"),
+		filepath.Join(tempDir, "derived.h"),
+		filepath.Join(tempDir, "missing2.h"),
+	}
+
+	a := NewCppAnalyzer()
+	classes, err := a.AnalyzeFiles(paths)
+	var failed *AnalysisError
+	if !errors.As(err, &failed) || len(failed.Files) != 2 {
+		t.Fatalf("应汇总2个分析失败的文件，实际: %v", err)
+	}
+	if failed.Files[0].Path != paths[1] || failed.Files[1].Path != paths[3] {
+		t.Errorf("失败的文件不正确: %v", failed)
+	}
+	if len(classes) != 2 {
+		t.Errorf("应返回其余文件中的2个类，实际: %d", len(classes))
+	}
+	if derived := findClassByName(classes, "Derived"); derived == nil || derived.BaseClasses[0].External {
+		t.Error("其余文件之间的继承关系应正常解析")
+	}
+	if n := a.Diagnostics().Count(SeverityError); n != 2 {
+		t.Errorf("期望 2 个错误诊断，实际: %d", n)
+	}
+
+	// 立即停止时只分析第一个失败的文件之前的文件
+	a.SetFailFast(true)
+	classes, err = a.AnalyzeFiles(paths)
+	if !errors.As(err, &failed) || len(failed.Files) != 1 || failed.Files[0].Path != paths[1] {
+		t.Fatalf("应在第一个失败的文件处停止，实际: %v", err)
+	}
+	if len(classes) != 1 || classes[0].Name != "Base" {
+		t.Errorf("应只返回失败之前分析的类，实际: %d 个", len(classes))
+	}
+
+	if _, err := a.AnalyzeProject(filepath.Join(tempDir, "nope")); err == nil || errors.As(err, &failed) {
+		t.Errorf("项目目录不存在时应返回普通错误，实际: %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package analyzer
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// FileKind C++文件的种类
+type FileKind string
+
+const (
+	FileHeader FileKind = "header" // 头文件，如 .h、.hpp、.inl 和没有扩展名的头文件
+	FileSource FileKind = "source" // 源文件，如 .cpp、.cu
+	FileModule FileKind = "module" // 模块接口单元，如 .ixx、.cppm
+)
+
+// Description 返回种类的中文描述
+func (k FileKind) Description() string {
+	switch k {
+	case FileHeader:
+		return "头文件"
+	case FileSource:
+		return "源文件"
+	case FileModule:
+		return "模块接口单元"
+	}
+	return "未知"
+}
+
+// DefaultExtensions 默认识别的C++文件扩展名及其种类
+var DefaultExtensions = map[string]FileKind{
+	".h": FileHeader, ".hh": FileHeader, ".hpp": FileHeader, ".hxx": FileHeader, ".h++": FileHeader,
+	".inl": FileHeader, ".ipp": FileHeader, ".tpp": FileHeader, ".cuh": FileHeader,
+	".cpp": FileSource, ".cxx": FileSource, ".cc": FileSource, ".c++": FileSource, ".cu": FileSource,
+	".ixx": FileModule, ".cppm": FileModule,
+}
+
+// sniffSize 判断没有扩展名的文件是否为C++头文件时读取的字节数
+const sniffSize = 4096
+
+// SetExtension 设置扩展名对应的文件种类，如 SetExtension(".inc", FileHeader)；kind 为空时不再把该扩展名识别为C++文件
+// 扩展名不区分大小写，可以省略开头的点
+func (a *CppAnalyzer) SetExtension(ext string, kind FileKind) error {
+	if kind != "" && kind != FileHeader && kind != FileSource && kind != FileModule {
+		return fmt.Errorf("不支持的文件种类: %s (可选: header, source, module)", kind)
+	}
+	ext = strings.ToLower(ext)
+	if ext == "" || ext == "." {
+		return fmt.Errorf("无效的扩展名: %q", ext)
+	}
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	if kind == "" {
+		delete(a.extensions, ext)
+	} else {
+		a.extensions[ext] = kind
+	}
+	return nil
+}
+
+// FileKind 返回文件的种类，不是可识别的C++文件时返回空
+// 先按扩展名判断；没有扩展名的文件(如 include/QWidget)读取开头的内容，像C++代码时作为头文件
+func (a *CppAnalyzer) FileKind(filePath string) FileKind {
+	if kind := a.extensionKind(filePath); kind != "" {
+		return kind
+	}
+	if filepath.Ext(filePath) == "" && a.sniffCpp(filePath) {
+		return FileHeader
+	}
+	return ""
+}
+
+// extensionKind 只按扩展名返回文件的种类，不读取文件
+func (a *CppAnalyzer) extensionKind(filePath string) FileKind {
+	return a.extensions[strings.ToLower(filepath.Ext(filePath))]
+}
+
+// isCppFile 检查文件是否为C++源文件
+func (a *CppAnalyzer) isCppFile(filePath string) bool {
+	return a.FileKind(filePath) != ""
+}
+
+// cppMarkers 出现在行首时表明文件是C++代码的内容
+var cppMarkers = []string{
+	"#include", "#pragma once", "#ifndef", "#define", "#if defined",
+	"namespace ", "class ", "struct ", "template<", "template <", "extern \"C",
+}
+
+// sniffCpp 读取文件开头的内容，判断没有扩展名的文件是否为C++代码
+// 内容按 decodeSource 解码；二进制文件和以 #! 开头的脚本不是；跳过注释后，第一行有效内容以预处理指令或声明开始时是
+func (a *CppAnalyzer) sniffCpp(filePath string) bool {
+	file, err := a.openSource(filePath)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+	buf := make([]byte, sniffSize)
+	n, _ := io.ReadFull(file, buf)
+	head, _, _ := decodeSource(buf[:n])
+	if strings.IndexByte(head, 0) >= 0 || strings.HasPrefix(head, "#!") {
+		return false
+	}
+
+	inComment := false
+	for _, line := range strings.Split(string(head), "\n") {
+		line = strings.TrimSpace(line)
+		if inComment {
+			end := strings.Index(line, "*/")
+			if end < 0 {
+				continue
+			}
+			inComment = false
+			line = strings.TrimSpace(line[end+2:])
+		}
+		if strings.HasPrefix(line, "/*") {
+			if end := strings.Index(line[2:], "*/"); end >= 0 {
+				line = strings.TrimSpace(line[end+4:])
+			} else {
+				inComment = true
+				continue
+			}
+		}
+		if line == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		for _, marker := range cppMarkers {
+			if strings.HasPrefix(line, marker) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
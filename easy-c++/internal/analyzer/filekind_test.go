@@ -0,0 +1,80 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestFileKind 测试按扩展名和文件内容识别C++文件的种类
+func TestFileKind(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"include/QWidget": "/* Copyright\n * Qt */\n\n#pragma once\nclass QWidget {};\n",
+		"include/vector":  "// -*- C++ -*-\nnamespace std { template<class T> class vector {}; }\n",
+		"stack.tpp":       "template<class T> struct Stack {};\n",
+		"kernel.cu":       "struct Kernel {};\n",
+		"shapes.cppm":     "export module shapes;\nexport class Shape {};\n",
+		"widget.CPP":      "class Widget : public QWidget {};\n",
+		"Makefile":        "# build rules\nall:\n\tg++ main.cpp\n",
+		"configure":       "#!/bin/sh\n#include nothing\n",
+		"LICENSE":         "MIT License\n\nclass action lawsuits...\n",
+		"notes.txt":       "class Notes {};\n",
+		"data":            "class\x00binary",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("创建目录失败: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+	}
+
+	a := NewCppAnalyzer()
+	expected := map[string]FileKind{
+		"include/QWidget": FileHeader,
+		"include/vector":  FileHeader,
+		"stack.tpp":       FileHeader,
+		"kernel.cu":       FileSource,
+		"shapes.cppm":     FileModule,
+		"widget.CPP":      FileSource,
+		"Makefile":        "",
+		"configure":       "",
+		"LICENSE":         "",
+		"notes.txt":       "",
+		"data":            "",
+	}
+	for name, kind := range expected {
+		if got := a.FileKind(filepath.Join(tempDir, filepath.FromSlash(name))); got != kind {
+			t.Errorf("%s 的种类应为 %q，实际: %q", name, kind, got)
+		}
+	}
+
+	classes, err := a.AnalyzeProject(tempDir)
+	if err != nil {
+		t.Fatalf("项目分析失败: %v", err)
+	}
+	kinds := make(map[string]FileKind)
+	for _, class := range classes {
+		kinds[class.QualifiedName] = class.FileKind
+	}
+	if len(kinds) != 6 || kinds["QWidget"] != FileHeader || kinds["Shape"] != FileModule || kinds["Widget"] != FileSource {
+		t.Errorf("类所在文件的种类不正确: %v", kinds)
+	}
+
+	// 自定义扩展名
+	if err := a.SetExtension("txt", FileHeader); err != nil {
+		t.Fatalf("设置扩展名失败: %v", err)
+	}
+	if err := a.SetExtension(".cu", ""); err != nil {
+		t.Fatalf("移除扩展名失败: %v", err)
+	}
+	if a.FileKind(filepath.Join(tempDir, "notes.txt")) != FileHeader || a.FileKind(filepath.Join(tempDir, "kernel.cu")) != "" {
+		t.Error("自定义扩展名未生效")
+	}
+	if err := a.SetExtension(".x", "library"); err == nil {
+		t.Error("不支持的文件种类应返回错误")
+	}
+}
@@ -0,0 +1,219 @@
+package analyzer
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Include 表示一条 #include 指令
+type Include struct {
+	Name     string // 指令中的文件名，如 geometry.h
+	Angled   bool   // 是否为 <...> 形式
+	Resolved string // 解析得到的文件路径，未找到时为空
+	Line     int    // 指令所在行号
+}
+
+// String 返回指令中的写法，如 "geometry.h" 或 <vector>
+func (inc Include) String() string {
+	if inc.Angled {
+		return "<" + inc.Name + ">"
+	}
+	return `"` + inc.Name + `"`
+}
+
+// IncludeGraph 文件级的包含关系图
+type IncludeGraph struct {
+	Files    []string             // 参与分析的文件，按分析顺序排列
+	Includes map[string][]Include // 每个文件中活动分支里的 #include 指令
+
+	visible map[string]map[string]bool // visibleFrom 的缓存
+}
+
+// newIncludeGraph 创建空的包含关系图
+func newIncludeGraph() *IncludeGraph {
+	return &IncludeGraph{Includes: make(map[string][]Include)}
+}
+
+// addFile 记录一个文件及其 #include 指令
+func (g *IncludeGraph) addFile(filePath string, includes []Include) {
+	if _, exists := g.Includes[filePath]; !exists {
+		g.Files = append(g.Files, filePath)
+	}
+	g.Includes[filePath] = includes
+	g.visible = nil
+}
+
+// Closure 返回从 filePath 出发直接或间接包含的所有文件，含自身，按首次到达的顺序排列
+func (g *IncludeGraph) Closure(filePath string) []string {
+	seen := map[string]bool{filePath: true}
+	result := []string{filePath}
+	for i := 0; i < len(result); i++ {
+		for _, inc := range g.Includes[result[i]] {
+			if inc.Resolved != "" && !seen[inc.Resolved] {
+				seen[inc.Resolved] = true
+				result = append(result, inc.Resolved)
+			}
+		}
+	}
+	return result
+}
+
+// Includers 返回直接包含 filePath 的文件
+func (g *IncludeGraph) Includers(filePath string) []string {
+	var result []string
+	for _, file := range g.Files {
+		for _, inc := range g.Includes[file] {
+			if inc.Resolved == filePath {
+				result = append(result, file)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// participates 判断文件是否与其他文件有包含关系，没有包含关系的文件无法据此判断可见性
+func (g *IncludeGraph) participates(filePath string) bool {
+	for _, inc := range g.Includes[filePath] {
+		if inc.Resolved != "" {
+			return true
+		}
+	}
+	return len(g.Includers(filePath)) > 0
+}
+
+// visibleFrom 返回在 filePath 中可见的文件: 所有包含了 filePath 的翻译单元中被包含的文件
+// 头文件依赖于包含它的源文件事先包含的其他头文件时，这些头文件也视为可见
+func (g *IncludeGraph) visibleFrom(filePath string) map[string]bool {
+	if g.visible == nil {
+		g.visible = make(map[string]map[string]bool)
+		for _, unit := range g.Files {
+			closure := g.Closure(unit)
+			for _, file := range closure {
+				if g.visible[file] == nil {
+					g.visible[file] = make(map[string]bool)
+				}
+				for _, other := range closure {
+					g.visible[file][other] = true
+				}
+			}
+		}
+	}
+	return g.visible[filePath]
+}
+
+// Visible 判断 from 所在文件中能否看到 to 的定义
+// 任一方没有文件路径，或 from 所在文件没有任何包含关系时视为可见
+func (g *IncludeGraph) Visible(from, to *CppClass) bool {
+	if g == nil || from.FilePath == "" || to.FilePath == "" || from.FilePath == to.FilePath {
+		return true
+	}
+	if !g.participates(from.FilePath) {
+		return true
+	}
+	return g.visibleFrom(from.FilePath)[to.FilePath]
+}
+
+// DOT 以 Graphviz DOT 格式导出包含关系图，未找到的头文件以虚线框表示
+func (g *IncludeGraph) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph includes {\n")
+	sb.WriteString("    rankdir=LR;\n")
+	sb.WriteString("    node [shape=box, fontname=\"Helvetica\"];\n")
+	for _, file := range g.Files {
+		sb.WriteString(fmt.Sprintf("    %q [label=%q];\n", file, filepath.Base(file)))
+	}
+
+	missing := make(map[string]bool)
+	for _, file := range g.Files {
+		for _, inc := range g.Includes[file] {
+			target := inc.Resolved
+			if target == "" {
+				target = inc.String()
+				missing[target] = true
+			}
+			sb.WriteString(fmt.Sprintf("    %q -> %q;\n", file, target))
+		}
+	}
+	var names []string
+	for name := range missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sb.WriteString(fmt.Sprintf("    %q [style=dashed, color=gray];\n", name))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// IncludeGraph 返回最近一次分析得到的包含关系图
+func (a *CppAnalyzer) IncludeGraph() *IncludeGraph {
+	return a.includeGraph
+}
+
+// AddIncludePath 添加头文件搜索路径，等价于命令行的 -I dir
+func (a *CppAnalyzer) AddIncludePath(dir string) {
+	a.includePaths = append(a.includePaths, dir)
+}
+
+// resolveInclude 查找被包含的文件: "..." 形式先在包含者所在目录查找，再依次查找搜索路径
+// 找到的文件若已在分析列表中，返回列表中的写法，保证同一文件只有一个路径
+func (a *CppAnalyzer) resolveInclude(from string, inc Include) string {
+	var dirs []string
+	if !inc.Angled {
+		dirs = append(dirs, filepath.Dir(from))
+	}
+	dirs = append(dirs, a.includePaths...)
+	for _, dir := range dirs {
+		candidate := filepath.Join(dir, inc.Name)
+		if info, err := a.statSource(candidate); err != nil || info.IsDir() {
+			continue
+		}
+		if abs, err := filepath.Abs(candidate); err == nil {
+			if known, ok := a.knownFiles[abs]; ok {
+				return known
+			}
+			a.knownFiles[abs] = candidate
+		}
+		return candidate
+	}
+	return ""
+}
+
+// registerFiles 登记参与分析的文件，用于把包含指令解析到同一个路径
+func (a *CppAnalyzer) registerFiles(paths []string) {
+	for _, path := range paths {
+		if abs, err := filepath.Abs(path); err == nil {
+			if _, exists := a.knownFiles[abs]; !exists {
+				a.knownFiles[abs] = path
+			}
+		}
+	}
+}
+
+// includeDirective 解析 #include 指令的内容，宏形式的指令先展开宏
+func (pp *preprocessor) includeDirective(rest string, line int) (Include, bool) {
+	rest = strings.TrimSpace(rest)
+	if !strings.HasPrefix(rest, `"`) && !strings.HasPrefix(rest, "<") {
+		// #include CONFIG_HEADER
+		var sb strings.Builder
+		for _, t := range pp.expand(Tokenize(rest)) {
+			sb.WriteString(t.Text)
+		}
+		rest = sb.String()
+	}
+	switch {
+	case strings.HasPrefix(rest, `"`):
+		if end := strings.Index(rest[1:], `"`); end >= 0 {
+			return Include{Name: rest[1 : end+1], Line: line}, true
+		}
+	case strings.HasPrefix(rest, "<"):
+		if end := strings.Index(rest, ">"); end >= 0 {
+			return Include{Name: rest[1:end], Angled: true, Line: line}, true
+		}
+	}
+	return Include{}, false
+}
@@ -0,0 +1,103 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestAnalyzeFiles_Includes 测试 #include 解析、包含关系图以及按可见性查找基类
+func TestAnalyzeFiles_Includes(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"inc/shapes/shape.h": `#pragma once
+class Shape {
+public:
+    virtual void draw() = 0;
+};
+`,
+		"legacy/shape.h": `class Shape {
+    int id;
+};
+`,
+		"src/circle.h": `#include <shapes/shape.h>
+class Circle : public Shape {};
+`,
+		"src/square.h": `class Square : public Shape {};
+`,
+		"src/config.h": `class Config {};
+`,
+		"src/main.cpp": `#include "circle.h"
+#include "square.h"
+#include <vector>
+#define CONFIG "config.h"
+#include CONFIG
+#if 0
+#include "disabled.h"
+#endif
+`,
+	}
+	path := func(name string) string {
+		return filepath.Join(tempDir, filepath.FromSlash(name))
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path(name)), 0755); err != nil {
+			t.Fatalf("创建目录失败: %v", err)
+		}
+		if err := os.WriteFile(path(name), []byte(content), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+	}
+
+	a := NewCppAnalyzer()
+	a.AddIncludePath(path("inc"))
+	classes, err := a.AnalyzeFiles([]string{path("src/main.cpp"), path("inc/shapes/shape.h"), path("legacy/shape.h")})
+	if err != nil {
+		t.Fatalf("多文件分析失败: %v", err)
+	}
+
+	// 被包含的头文件也参与分析
+	for _, name := range []string{"Circle", "Square", "Config"} {
+		if findClassByName(classes, name) == nil {
+			t.Errorf("未找到被包含的头文件中的类 %s", name)
+		}
+	}
+	// legacy/shape.h 中的 Shape 在 main.cpp 的翻译单元中不可见，基类应解析为 inc 中的 Shape
+	for _, name := range []string{"Circle", "Square"} {
+		if class := findClassByName(classes, name); class != nil && !sliceEqual(class.PureVirtuals, []string{"draw()"}) {
+			t.Errorf("类 %s 应继承可见的 Shape 中的纯虚函数 draw()，实际: %v", name, class.PureVirtuals)
+		}
+	}
+
+	graph := a.IncludeGraph()
+	var names []string
+	for _, inc := range graph.Includes[path("src/main.cpp")] {
+		names = append(names, inc.String())
+	}
+	if expected := []string{`"circle.h"`, `"square.h"`, "<vector>", `"config.h"`}; !sliceEqual(names, expected) {
+		t.Errorf("main.cpp 的包含指令不正确，期望: %v，实际: %v", expected, names)
+	}
+	if inc := graph.Includes[path("src/circle.h")]; len(inc) != 1 || inc[0].Resolved != path("inc/shapes/shape.h") {
+		t.Errorf("<shapes/shape.h> 应通过搜索路径解析，实际: %v", inc)
+	}
+
+	closure := graph.Closure(path("src/main.cpp"))
+	if len(closure) != 5 {
+		t.Errorf("main.cpp 应直接或间接包含4个文件，实际: %v", closure)
+	}
+	if includers := graph.Includers(path("inc/shapes/shape.h")); !sliceEqual(includers, []string{path("src/circle.h")}) {
+		t.Errorf("shape.h 的包含者不正确: %v", includers)
+	}
+
+	dot := graph.DOT()
+	for _, want := range []string{
+		"digraph includes {",
+		`"` + path("src/circle.h") + `" -> "` + path("inc/shapes/shape.h") + `"`,
+		`"<vector>" [style=dashed`,
+	} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("DOT 输出缺少 %s:\n%s", want, dot)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package analyzer
+
+import (
+	"strings"
+)
+
+// classIndex 按限定名索引类，用于基类名查找
+type classIndex struct {
+	byName  map[string][]*CppClass
+	visible func(from, to *CppClass) bool // from 中能否看到 to 的定义
+}
+
+// newClassIndex 为类列表建立索引，visible 为 nil 时所有类互相可见
+// 内联命名空间中的类同时以省略内联命名空间后的名字登记
+func newClassIndex(classes []*CppClass, inlineNamespaces map[string]bool, visible func(from, to *CppClass) bool) *classIndex {
+	idx := &classIndex{byName: make(map[string][]*CppClass), visible: visible}
+	for _, class := range classes {
+		idx.byName[class.QualifiedName] = append(idx.byName[class.QualifiedName], class)
+	}
+	aliases := make(map[string][]*CppClass)
+	for _, class := range classes {
+		alias := elideInlineNamespaces(class.QualifiedName, inlineNamespaces)
+		if _, exists := idx.byName[alias]; !exists {
+			aliases[alias] = append(aliases[alias], class)
+		}
+	}
+	for alias, list := range aliases {
+		idx.byName[alias] = list
+	}
+	return idx
+}
+
+// find 返回名为 key 且对 self 可见的类，有多个同名定义时取最后一个
+func (idx *classIndex) find(key string, self *CppClass) *CppClass {
+	candidates := idx.byName[key]
+	for i := len(candidates) - 1; i >= 0; i-- {
+		class := candidates[i]
+		if class == self {
+			continue
+		}
+		if self == nil || idx.visible == nil || idx.visible(self, class) {
+			return class
+		}
+	}
+	return nil
+}
+
+// lookup 按C++名字查找规则，从scope出发由内向外查找名为name的类
+// name 可以是 Base、ns::Base 或 ::Base 形式；self 为发起查找的类，不会被自身匹配
+func (idx *classIndex) lookup(name, scope string, self *CppClass) *CppClass {
+	if strings.HasPrefix(name, "::") {
+		return idx.find(strings.TrimPrefix(name, "::"), self)
+	}
+	for {
+		key := name
+		if scope != "" {
+			key = scope + "::" + name
+		}
+		if class := idx.find(key, self); class != nil {
+			return class
+		}
+		if scope == "" {
+			return nil
+		}
+		scope = parentScope(scope)
+	}
+}
+
+// parentScope 返回限定名的外层作用域，如 a::b::C 返回 a::b
+func parentScope(name string) string {
+	parts := splitQualifiedName(name)
+	if len(parts) <= 1 {
+		return ""
+	}
+	return strings.Join(parts[:len(parts)-1], "::")
+}
+
+// splitQualifiedName 按 :: 切分限定名，忽略模板实参中的 ::
+func splitQualifiedName(name string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(name); i++ {
+		switch {
+		case name[i] == '<':
+			depth++
+		case name[i] == '>':
+			depth--
+		case depth == 0 && strings.HasPrefix(name[i:], "::"):
+			parts = append(parts, name[start:i])
+			start = i + 2
+			i++
+		}
+	}
+	return append(parts, name[start:])
+}
+
+// elideInlineNamespaces 去掉限定名中的内联命名空间，如 lib::v2::Foo 变为 lib::Foo
+func elideInlineNamespaces(name string, inlineNamespaces map[string]bool) string {
+	if len(inlineNamespaces) == 0 {
+		return name
+	}
+	var prefix string
+	var visible []string
+	for _, part := range splitQualifiedName(name) {
+		if prefix == "" {
+			prefix = part
+		} else {
+			prefix += "::" + part
+		}
+		if !inlineNamespaces[prefix] {
+			visible = append(visible, part)
+		}
+	}
+	return strings.Join(visible, "::")
+}
@@ -0,0 +1,246 @@
+package analyzer
+
+import (
+	"strconv"
+	"strings"
+)
+
+// maxExpansions 单个文件中宏展开次数的上限，防止病态的宏定义导致展开结果爆炸
+const maxExpansions = 100000
+
+// hideSet 记录一个词法单元来自哪些宏的展开，这些宏不再对它展开，避免无限递归
+type hideSet struct {
+	name string
+	next *hideSet
+}
+
+// contains 判断 name 是否在集合中
+func (h *hideSet) contains(name string) bool {
+	for ; h != nil; h = h.next {
+		if h.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// union 返回 h 与 other 的并集
+func (h *hideSet) union(other *hideSet) *hideSet {
+	result := h
+	for ; other != nil; other = other.next {
+		if !result.contains(other.name) {
+			result = &hideSet{name: other.name, next: result}
+		}
+	}
+	return result
+}
+
+// ppToken 宏展开过程中带隐藏集的词法单元
+type ppToken struct {
+	Token
+	hide  *hideSet
+	paste bool // 替换列表中的 ## 运算符
+}
+
+// expand 展开词法单元序列中的宏，展开结果取宏调用处的行列号
+// 替换结果与其后的词法单元一起重新扫描，因此 #define DECL DECLARE 之后 DECL(Foo) 也能展开
+func (pp *preprocessor) expand(toks []Token) []Token {
+	items := make([]ppToken, len(toks))
+	for i, t := range toks {
+		items[i] = ppToken{Token: t}
+	}
+	var out []Token
+	for _, item := range pp.expandTokens(items) {
+		out = append(out, item.Token)
+	}
+	return out
+}
+
+// expandTokens 对带隐藏集的词法单元序列做宏替换和重新扫描
+// 待处理的词法单元倒序存放在栈中，栈顶为下一个词法单元
+func (pp *preprocessor) expandTokens(items []ppToken) []ppToken {
+	stack := make([]ppToken, len(items))
+	for i, item := range items {
+		stack[len(items)-1-i] = item
+	}
+
+	var out []ppToken
+	for len(stack) > 0 {
+		t := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		macro := pp.macros[t.Text]
+		if t.Kind != TokenIdent || macro == nil || t.hide.contains(t.Text) || pp.expansions >= maxExpansions {
+			out = append(out, t)
+			continue
+		}
+
+		var args [][]ppToken
+		if macro.FunctionLike {
+			var n int
+			var ok bool
+			if args, n, ok = collectMacroArgs(stack); !ok {
+				// 函数式宏名之后没有实参列表时不展开
+				out = append(out, t)
+				continue
+			}
+			stack = stack[:len(stack)-n]
+		}
+		pp.expansions++
+
+		body := pp.substitute(macro, args, t.hide.union(&hideSet{name: macro.Name}), t.Token)
+		for i := len(body) - 1; i >= 0; i-- {
+			stack = append(stack, body[i])
+		}
+	}
+	return out
+}
+
+// collectMacroArgs 从栈顶读取函数式宏调用的实参列表 (a, b)，返回实参、消耗的词法单元数和是否成功
+// 实参只按圆括号之外的逗号切分，std::map<K, V> 这样的实参需要加括号
+func collectMacroArgs(stack []ppToken) ([][]ppToken, int, bool) {
+	top := len(stack) - 1
+	if top < 0 || stack[top].Text != "(" {
+		return nil, 0, false
+	}
+	var args [][]ppToken
+	var current []ppToken
+	depth := 0
+	for i := top; i >= 0; i-- {
+		t := stack[i]
+		switch t.Text {
+		case "(":
+			depth++
+			if depth == 1 {
+				continue
+			}
+		case ")":
+			depth--
+			if depth == 0 {
+				return append(args, current), top - i + 1, true
+			}
+		case ",":
+			if depth == 1 {
+				args = append(args, current)
+				current = nil
+				continue
+			}
+		}
+		current = append(current, t)
+	}
+	return nil, 0, false
+}
+
+// substitute 用实参替换宏的替换列表，处理 #、## 和 __VA_ARGS__/__VA_OPT__
+func (pp *preprocessor) substitute(macro *Macro, args [][]ppToken, hide *hideSet, site Token) []ppToken {
+	params := make(map[string]int)
+	for i, name := range macro.Params {
+		params[name] = i
+	}
+	if len(macro.Params) == 0 && len(args) == 1 && len(args[0]) == 0 {
+		// F() 的空实参列表
+		args = nil
+	}
+	arg := func(name string) ([]ppToken, bool) {
+		if macro.Variadic && name == "__VA_ARGS__" {
+			var va []ppToken
+			for i := len(macro.Params); i < len(args); i++ {
+				if i > len(macro.Params) {
+					va = append(va, ppToken{Token: Token{Kind: TokenPunct, Text: ","}})
+				}
+				va = append(va, args[i]...)
+			}
+			return va, true
+		}
+		i, ok := params[name]
+		if !ok || !macro.FunctionLike {
+			return nil, false
+		}
+		if i < len(args) {
+			return args[i], true
+		}
+		return nil, true
+	}
+
+	var result []ppToken
+	body := macro.Body
+	for i := 0; i < len(body); i++ {
+		t := body[i]
+		switch {
+		case macro.FunctionLike && t.Text == "#" && i+1 < len(body):
+			if raw, ok := arg(body[i+1].Text); ok {
+				result = append(result, ppToken{Token: stringize(raw)})
+				i++
+				continue
+			}
+		case t.Text == "##":
+			result = append(result, ppToken{Token: t, paste: true})
+			continue
+		case t.Text == "__VA_OPT__" && macro.Variadic && i+1 < len(body) && body[i+1].Text == "(":
+			optional, end := bracketContents(body, i+1)
+			if va, _ := arg("__VA_ARGS__"); len(va) > 0 {
+				inner := &Macro{Name: macro.Name, FunctionLike: true, Params: macro.Params, Variadic: true, Body: optional}
+				result = append(result, pp.substitute(inner, args, nil, site)...)
+			}
+			i = end - 1
+			continue
+		}
+
+		if raw, ok := arg(t.Text); ok {
+			adjacentPaste := (i > 0 && body[i-1].Text == "##") || (i+1 < len(body) && body[i+1].Text == "##")
+			switch {
+			case adjacentPaste && len(raw) == 0:
+				// 空实参作为占位符参与拼接
+				result = append(result, ppToken{Token: Token{Kind: TokenPunct}})
+			case adjacentPaste:
+				result = append(result, raw...)
+			default:
+				result = append(result, pp.expandTokens(raw)...)
+			}
+			continue
+		}
+		result = append(result, ppToken{Token: t})
+	}
+
+	var expanded []ppToken
+	for _, t := range pasteTokens(result) {
+		if t.Text == "" {
+			continue
+		}
+		t.Line, t.Column = site.Line, site.Column
+		t.hide = t.hide.union(hide)
+		expanded = append(expanded, t)
+	}
+	return expanded
+}
+
+// pasteTokens 执行 ## 运算符，把两侧的词法单元拼接成一个
+func pasteTokens(toks []ppToken) []ppToken {
+	var result []ppToken
+	for i := 0; i < len(toks); i++ {
+		if !toks[i].paste {
+			result = append(result, toks[i])
+			continue
+		}
+		if len(result) == 0 || i+1 >= len(toks) {
+			continue
+		}
+		left := result[len(result)-1]
+		right := toks[i+1]
+		i++
+		pasted := Tokenize(left.Text + right.Text)
+		result = result[:len(result)-1]
+		for _, t := range pasted {
+			result = append(result, ppToken{Token: t, hide: left.hide})
+		}
+	}
+	return result
+}
+
+// stringize 执行 # 运算符，把实参转换为字符串字面量
+func stringize(toks []ppToken) Token {
+	plain := make([]Token, len(toks))
+	for i, t := range toks {
+		plain[i] = t.Token
+	}
+	return Token{Kind: TokenString, Text: strconv.Quote(strings.TrimSpace(joinTokens(plain)))}
+}
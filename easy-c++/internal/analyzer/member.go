@@ -0,0 +1,167 @@
+package analyzer
+
+import (
+	"strings"
+)
+
+// Member 表示类的一个成员变量
+type Member struct {
+	Name         string   // 成员名
+	Type         string   // 完整类型，含 cv 限定和指针/引用，如 const char* const
+	Access       string   // 访问级别: public、protected 或 private
+	Static       bool     // 是否为 static 成员
+	Mutable      bool     // 是否为 mutable 成员
+	Constexpr    bool     // 是否为 constexpr 成员
+	Const        bool     // 成员本身是否不可修改(顶层 const 或 constexpr)
+	ArrayExtents []string // 数组各维长度，如 data[4][N] 为 4 和 N，未指定长度为空字符串
+	BitWidth     string   // 位域宽度，非位域为空
+	Default      string   // 默认成员初始化器，= 形式不含等号，花括号形式含花括号
+	LineNumber   int      // 声明所在行号
+}
+
+// String 返回成员的声明形式，如 static constexpr int kMax = 10
+func (m Member) String() string {
+	var sb strings.Builder
+	if m.Static {
+		sb.WriteString("static ")
+	}
+	if m.Mutable {
+		sb.WriteString("mutable ")
+	}
+	if m.Constexpr {
+		sb.WriteString("constexpr ")
+	}
+	sb.WriteString(m.Type)
+	sb.WriteString(" ")
+	sb.WriteString(m.Name)
+	for _, extent := range m.ArrayExtents {
+		sb.WriteString("[" + extent + "]")
+	}
+	if m.BitWidth != "" {
+		sb.WriteString(" : " + m.BitWidth)
+	}
+	switch {
+	case m.Default == "":
+	case strings.HasPrefix(m.Default, "{"):
+		sb.WriteString(m.Default)
+	default:
+		sb.WriteString(" = " + m.Default)
+	}
+	return sb.String()
+}
+
+// parseMembers 将一条数据成员声明解析为成员列表，如 int a = 1, *b, c[4];
+func parseMembers(decl []Token, access string) []Member {
+	var static, mutable, constexpr bool
+	var specs []Token
+	for _, t := range decl {
+		switch t.Text {
+		case "static":
+			static = true
+		case "mutable":
+			mutable = true
+		case "constexpr":
+			constexpr = true
+		}
+	}
+
+	var members []Member
+	declarators := splitTopLevel(decl, ",")
+	for i, declarator := range declarators {
+		n := declaratorNameIndex(declarator)
+		if n < 0 || declarator[n].Kind != TokenIdent {
+			continue
+		}
+
+		var typeToks []Token
+		if i == 0 {
+			typeToks = stripSpecifiers(declarator[:n])
+			// 后续声明符只沿用说明符部分，int* a, b 中 b 的类型为 int
+			specs = typeToks[:firstPtrOperator(typeToks)]
+		} else {
+			typeToks = append(append([]Token{}, specs...), declarator[:n]...)
+		}
+		if len(typeToks) == 0 {
+			continue
+		}
+
+		member := Member{
+			Name:       declarator[n].Text,
+			Type:       joinTokens(typeToks),
+			Access:     access,
+			Static:     static,
+			Mutable:    mutable,
+			Constexpr:  constexpr,
+			Const:      constexpr || isTopLevelConst(typeToks),
+			LineNumber: declarator[n].Line,
+		}
+		parseDeclaratorSuffix(&member, declarator[n+1:])
+		members = append(members, member)
+	}
+	return members
+}
+
+// parseDeclaratorSuffix 解析声明符名字之后的数组维度、位域宽度和默认初始化器
+func parseDeclaratorSuffix(member *Member, toks []Token) {
+	for i := 0; i < len(toks); {
+		switch toks[i].Text {
+		case "[":
+			extent, end := bracketContents(toks, i)
+			member.ArrayExtents = append(member.ArrayExtents, joinTokens(extent))
+			i = end
+		case ":":
+			end := i + 1
+			for end < len(toks) && toks[end].Text != "=" && toks[end].Text != "{" {
+				end++
+			}
+			member.BitWidth = joinTokens(toks[i+1 : end])
+			i = end
+		case "=":
+			member.Default = joinTokens(toks[i+1:])
+			return
+		case "{":
+			member.Default = joinTokens(toks[i:])
+			return
+		default:
+			i++
+		}
+	}
+}
+
+// firstPtrOperator 返回类型中第一个顶层指针或引用运算符的位置，没有时返回序列长度
+func firstPtrOperator(toks []Token) int {
+	first := len(toks)
+	for _, op := range []string{"*", "&", "&&"} {
+		if i := findTopLevel(toks, op); i >= 0 && i < first {
+			first = i
+		}
+	}
+	return first
+}
+
+// isTopLevelConst 判断类型本身是否为 const，const char* 指向常量但本身可修改，
+// const char* const 和 const int 本身不可修改，引用不视为 const
+func isTopLevelConst(toks []Token) bool {
+	mask := topLevelMask(toks)
+	start := 0
+	for i := len(toks) - 1; i >= 0; i-- {
+		if !mask[i] {
+			continue
+		}
+		switch toks[i].Text {
+		case "&", "&&":
+			return false
+		case "*":
+			start = i + 1
+		}
+		if start > 0 {
+			break
+		}
+	}
+	for i := start; i < len(toks); i++ {
+		if mask[i] && toks[i].Text == "const" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,385 @@
+package analyzer
+
+import (
+	"strings"
+)
+
+// MethodKind 成员方法的种类
+type MethodKind string
+
+const (
+	MethodNormal      MethodKind = "method"      // 普通成员方法
+	MethodConstructor MethodKind = "constructor" // 构造函数
+	MethodDestructor  MethodKind = "destructor"  // 析构函数
+	MethodOperator    MethodKind = "operator"    // 运算符重载，如 operator==
+	MethodConversion  MethodKind = "conversion"  // 类型转换运算符，如 operator bool
+)
+
+// Parameter 表示方法的一个形参
+type Parameter struct {
+	Type    string // 形参类型，如 const std::string&
+	Name    string // 形参名，未命名时为空
+	Default string // 默认实参
+}
+
+// String 返回形参的源代码形式，函数指针和数组形参的名字放回声明符内部
+func (p Parameter) String() string {
+	s := p.Type
+	switch {
+	case p.Name == "":
+	case strings.Contains(s, "(*)") || strings.Contains(s, "(&)"):
+		i := max(strings.Index(s, "(*)"), strings.Index(s, "(&)")) + 2
+		s = s[:i] + p.Name + s[i:]
+	case strings.HasSuffix(s, "]") && strings.Contains(s, "["):
+		i := strings.Index(s, "[")
+		s = s[:i] + " " + p.Name + s[i:]
+	default:
+		s += " " + p.Name
+	}
+	if p.Default != "" {
+		s += " = " + p.Default
+	}
+	return s
+}
+
+// Method 表示类的一个成员方法
+type Method struct {
+	Name           string          // 方法名，如 speak、~Shape、operator==、operator bool
+	Kind           MethodKind      // 方法种类
+	ReturnType     string          // 返回类型，构造函数和析构函数为空
+	Params         []Parameter     // 形参列表
+	Variadic       bool            // 是否有 C 风格可变参数 ...
+	Access         string          // 访问级别: public、protected 或 private
+	TemplateParams []TemplateParam // 成员函数模板的模板形参
+
+	Virtual      bool   // 是否声明为 virtual
+	Pure         bool   // 是否为纯虚函数 = 0
+	Override     bool   // 是否标记 override
+	Final        bool   // 是否标记 final
+	Static       bool   // 是否为 static 方法
+	Explicit     bool   // 是否为 explicit 构造函数或转换运算符
+	Constexpr    bool   // 是否为 constexpr 或 consteval
+	Const        bool   // 是否为 const 方法
+	Volatile     bool   // 是否为 volatile 方法
+	RefQualifier string // 引用限定符 & 或 &&
+	Noexcept     bool   // 是否为 noexcept
+	Defaulted    bool   // 是否为 = default
+	Deleted      bool   // 是否为 = delete
+
+	LineNumber int // 声明所在行号
+
+	DefinitionFile string // 定义所在的文件，在类体内定义时为类所在的文件
+	DefinitionLine int    // 定义所在的行号，未找到定义时为0
+	Undefined      bool   // 声明了但在分析的文件中没有找到定义
+}
+
+// String 返回方法的完整声明，如 virtual void draw(int n = 0) const override
+func (m Method) String() string {
+	var sb strings.Builder
+	if len(m.TemplateParams) > 0 {
+		var params []string
+		for _, tp := range m.TemplateParams {
+			params = append(params, tp.String())
+		}
+		sb.WriteString("template<" + strings.Join(params, ", ") + "> ")
+	}
+	for _, spec := range []struct {
+		on   bool
+		text string
+	}{
+		{m.Static, "static "},
+		{m.Explicit, "explicit "},
+		{m.Constexpr, "constexpr "},
+		{m.Virtual, "virtual "},
+	} {
+		if spec.on {
+			sb.WriteString(spec.text)
+		}
+	}
+	if m.ReturnType != "" && m.Kind != MethodConversion {
+		sb.WriteString(m.ReturnType + " ")
+	}
+	var params []string
+	for _, p := range m.Params {
+		params = append(params, p.String())
+	}
+	if m.Variadic {
+		params = append(params, "...")
+	}
+	sb.WriteString(m.Name + "(" + strings.Join(params, ", ") + ")")
+	sb.WriteString(m.qualifiers())
+	for _, spec := range []struct {
+		on   bool
+		text string
+	}{
+		{m.Noexcept, " noexcept"},
+		{m.Override, " override"},
+		{m.Final, " final"},
+		{m.Pure, " = 0"},
+		{m.Defaulted, " = default"},
+		{m.Deleted, " = delete"},
+	} {
+		if spec.on {
+			sb.WriteString(spec.text)
+		}
+	}
+	return sb.String()
+}
+
+// Signature 返回用于区分重载的签名，只含方法名、形参类型和限定符，如 draw(int) const
+func (m Method) Signature() string {
+	var types []string
+	for _, p := range m.Params {
+		types = append(types, p.Type)
+	}
+	if m.Variadic {
+		types = append(types, "...")
+	}
+	return m.Name + "(" + strings.Join(types, ", ") + ")" + m.qualifiers()
+}
+
+// qualifiers 返回方法的 cv 限定符和引用限定符
+func (m Method) qualifiers() string {
+	s := ""
+	if m.Const {
+		s += " const"
+	}
+	if m.Volatile {
+		s += " volatile"
+	}
+	if m.RefQualifier != "" {
+		s += " " + m.RefQualifier
+	}
+	return s
+}
+
+// parseMethod 将一条函数声明解析为成员方法，className 用于识别构造函数
+// 无法识别方法名或形参列表不完整时返回 false
+func parseMethod(decl []Token, className, access string) (Method, bool) {
+	method := Method{Kind: MethodNormal, Access: access}
+	if len(decl) > 0 {
+		method.LineNumber = decl[0].Line
+	}
+	if len(decl) > 1 && decl[0].Text == "template" && decl[1].Text == "<" {
+		inner, end := angleContents(decl, 1)
+		method.TemplateParams = parseTemplateParams(inner)
+		decl = decl[end:]
+	}
+
+	paren := findParamParen(decl)
+	if paren < 0 {
+		return method, false
+	}
+	// 形参列表缺少右括号，如文件在声明中间结束
+	closeParen := matchingClose(decl, paren)
+	if matchingOpen(decl, closeParen-1) != paren {
+		return method, false
+	}
+	nameStart := paren - 1
+	if op := findTopLevel(decl[:paren], "operator"); op >= 0 {
+		nameStart = op
+		parseOperatorName(&method, decl[op+1:paren])
+	} else {
+		if decl[nameStart].Kind != TokenIdent {
+			return method, false
+		}
+		method.Name = decl[nameStart].Text
+		if nameStart > 0 && decl[nameStart-1].Text == "~" {
+			nameStart--
+			method.Name = "~" + method.Name
+			method.Kind = MethodDestructor
+		}
+	}
+
+	var retType []Token
+	for i := 0; i < nameStart; i++ {
+		switch t := decl[i]; t.Text {
+		case "virtual":
+			method.Virtual = true
+		case "static":
+			method.Static = true
+		case "explicit":
+			method.Explicit = true
+			if i+1 < nameStart && decl[i+1].Text == "(" {
+				i = matchingClose(decl, i+1) - 1
+			}
+		case "constexpr", "consteval":
+			method.Constexpr = true
+		case "inline", "friend", "extern":
+		case "[":
+			if i+1 < nameStart && decl[i+1].Text == "[" {
+				// [[nodiscard]] 等属性
+				i = matchingClose(decl, i) - 1
+				continue
+			}
+			retType = append(retType, t)
+		default:
+			retType = append(retType, t)
+		}
+	}
+	if method.Kind == MethodNormal && len(retType) == 0 && method.Name == className {
+		method.Kind = MethodConstructor
+	}
+	if method.Kind != MethodConversion {
+		method.ReturnType = joinTokens(retType)
+	}
+
+	parseParams(&method, decl[paren+1:closeParen-1])
+	parseMethodSuffix(&method, decl[closeParen:])
+	return method, true
+}
+
+// findParamParen 返回函数形参列表的左括号位置，跳过 explicit(...)、decltype(...)、属性等
+// 中的括号以及 operator() 的名字部分，未找到时返回-1
+func findParamParen(decl []Token) int {
+	mask := topLevelMask(decl)
+	for i, t := range decl {
+		if !mask[i] || t.Text != "(" {
+			continue
+		}
+		if i == 0 {
+			return -1
+		}
+		switch prev := decl[i-1].Text; prev {
+		case "explicit", "decltype", "alignas", "noexcept", "__attribute__", "__declspec":
+			continue
+		case "operator":
+			if i+1 < len(decl) && decl[i+1].Text == ")" {
+				// operator() 的第二对括号才是形参列表
+				continue
+			}
+		}
+		return i
+	}
+	return -1
+}
+
+// parseOperatorName 根据 operator 之后的词法单元设置运算符重载或类型转换运算符的名字
+func parseOperatorName(method *Method, toks []Token) {
+	if len(toks) == 0 {
+		return
+	}
+	switch first := toks[0]; {
+	case first.Kind == TokenPunct, first.Kind == TokenString,
+		first.Text == "new", first.Text == "delete", first.Text == "co_await":
+		method.Kind = MethodOperator
+		if first.Kind == TokenIdent {
+			method.Name = "operator " + joinTokens(toks)
+		} else {
+			method.Name = "operator" + joinTokens(toks)
+		}
+	default:
+		method.Kind = MethodConversion
+		method.ReturnType = joinTokens(toks)
+		method.Name = "operator " + method.ReturnType
+	}
+}
+
+// parseParams 解析形参列表
+func parseParams(method *Method, toks []Token) {
+	if len(toks) == 1 && toks[0].Text == "void" {
+		return
+	}
+	for _, param := range splitTopLevel(toks, ",") {
+		if len(param) == 1 && param[0].Text == "..." {
+			method.Variadic = true
+			continue
+		}
+		method.Params = append(method.Params, parseParam(param))
+	}
+}
+
+// parseParam 解析单个形参，如 const std::string& name = "x"
+func parseParam(toks []Token) Parameter {
+	var param Parameter
+	if eq := findTopLevel(toks, "="); eq >= 0 {
+		param.Default = joinTokens(toks[eq+1:])
+		toks = toks[:eq]
+	}
+
+	// 函数指针形参 void (*callback)(int) 的名字在括号内
+	for i := 0; i+3 < len(toks); i++ {
+		if toks[i].Text == "(" && (toks[i+1].Text == "*" || toks[i+1].Text == "&") &&
+			toks[i+2].Kind == TokenIdent && toks[i+3].Text == ")" {
+			param.Name = toks[i+2].Text
+			rest := append(append([]Token{}, toks[:i+2]...), toks[i+3:]...)
+			param.Type = joinTokens(rest)
+			return param
+		}
+	}
+
+	// 数组形参 int values[] 的维度属于类型
+	end := len(toks)
+	for end > 0 && toks[end-1].Text == "]" {
+		open := end - 1
+		for open > 0 && toks[open].Text != "[" {
+			open--
+		}
+		end = open
+	}
+	if n := end - 1; n > 0 && toks[n].Kind == TokenIdent && !isTypeKeyword(toks[n].Text) &&
+		toks[n-1].Text != "::" && !isCVQualifier(toks[n].Text) {
+		param.Name = toks[n].Text
+		rest := append(append([]Token{}, toks[:n]...), toks[end:]...)
+		param.Type = joinTokens(rest)
+		return param
+	}
+	param.Type = joinTokens(toks)
+	return param
+}
+
+// parseMethodSuffix 解析形参列表之后的限定符、尾置返回类型和 = 0/default/delete
+func parseMethodSuffix(method *Method, toks []Token) {
+	for i := 0; i < len(toks); i++ {
+		switch t := toks[i]; t.Text {
+		case "const":
+			method.Const = true
+		case "volatile":
+			method.Volatile = true
+		case "&", "&&":
+			method.RefQualifier = t.Text
+		case "override":
+			method.Override = true
+		case "final":
+			method.Final = true
+		case "noexcept":
+			method.Noexcept = true
+			if i+1 < len(toks) && toks[i+1].Text == "(" {
+				inner, end := bracketContents(toks, i+1)
+				method.Noexcept = joinTokens(inner) != "false"
+				i = end - 1
+			}
+		case "throw", "__attribute__", "requires":
+			if i+1 < len(toks) && toks[i+1].Text == "(" {
+				i = matchingClose(toks, i+1) - 1
+			}
+		case "[":
+			i = matchingClose(toks, i) - 1
+		case "->":
+			end := i + 1
+			for end < len(toks) && toks[end].Text != "=" && toks[end].Text != "override" &&
+				toks[end].Text != "final" && toks[end].Text != "requires" {
+				end++
+			}
+			method.ReturnType = joinTokens(toks[i+1 : end])
+			i = end - 1
+		case "=":
+			if i+1 < len(toks) {
+				switch toks[i+1].Text {
+				case "0":
+					method.Pure = true
+				case "default":
+					method.Defaulted = true
+				case "delete":
+					method.Deleted = true
+				}
+			}
+			return
+		}
+	}
+}
+
+// isCVQualifier 判断是否为 const 或 volatile
+func isCVQualifier(s string) bool {
+	return s == "const" || s == "volatile"
+}
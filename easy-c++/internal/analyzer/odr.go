@@ -0,0 +1,159 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ClassRedefinition 表示在多处定义的同名类
+type ClassRedefinition struct {
+	QualifiedName string
+	Definitions   []*CppClass // 各处定义，按分析顺序排列，含被合并掉的相同定义
+	Difference    string      // 与第一处定义的差异，如"基类不同、成员变量不同"；各处定义相同时为空
+}
+
+// Conflicting 判断各处定义是否不同，即违反了单一定义规则
+func (r ClassRedefinition) Conflicting() bool {
+	return r.Difference != ""
+}
+
+// Locations 返回各处定义的位置，如 include/shape.h:3
+func (r ClassRedefinition) Locations() []string {
+	var locations []string
+	for _, class := range r.Definitions {
+		locations = append(locations, fmt.Sprintf("%s:%d", class.FilePath, class.LineNumber))
+	}
+	return locations
+}
+
+// Redefinitions 返回最近一次分析中在多处定义的类
+func (a *CppAnalyzer) Redefinitions() []ClassRedefinition {
+	return a.redefinitions
+}
+
+// checkRedefinitions 检查同名类的多处定义，记录到 a.redefinitions，返回去重后的类列表
+// 与先前某处定义完全相同的重复定义(如同一个头文件的两份拷贝)被合并掉；
+// 各不相同的定义都保留并标记为 Conflicting。匿名命名空间中的类和局部类只在所在翻译单元内可见，不做检查
+func (a *CppAnalyzer) checkRedefinitions(classes []*CppClass) []*CppClass {
+	byName := make(map[string][]*CppClass)
+	var names []string
+	for _, class := range classes {
+		if class.AnonymousNamespace || class.EnclosingFunction != "" || strings.Contains(class.QualifiedName, "()") {
+			continue
+		}
+		if len(byName[class.QualifiedName]) == 0 {
+			names = append(names, class.QualifiedName)
+		}
+		byName[class.QualifiedName] = append(byName[class.QualifiedName], class)
+	}
+
+	merged := make(map[*CppClass]bool)
+	a.redefinitions = nil
+	for _, name := range names {
+		definitions := byName[name]
+		if len(definitions) < 2 {
+			continue
+		}
+		// 各不相同的定义，以第一次出现的为代表
+		var variants []*CppClass
+		var differences []string
+		for _, class := range definitions {
+			differences = appendUnique(differences, definitionDifference(definitions[0], class)...)
+			identical := false
+			for _, variant := range variants {
+				if len(definitionDifference(variant, class)) == 0 {
+					identical = true
+					break
+				}
+			}
+			if identical {
+				merged[class] = true
+			} else {
+				variants = append(variants, class)
+			}
+		}
+		if len(variants) > 1 {
+			for _, variant := range variants {
+				variant.Conflicting = true
+			}
+		}
+		first := definitions[0]
+		for _, class := range definitions[1:] {
+			if merged[class] {
+				a.report(SeverityNote, CodeDuplicateDefinition, class.FilePath, class.LineNumber, class.Column,
+					"类 %s 的定义与 %s:%d 处相同，已合并", name, first.FilePath, first.LineNumber)
+			} else {
+				a.report(SeverityWarning, CodeODRConflict, class.FilePath, class.LineNumber, class.Column,
+					"类 %s 的定义与 %s:%d 处的定义不一致(%s)", name, first.FilePath, first.LineNumber,
+					strings.Join(definitionDifference(first, class), "、"))
+			}
+		}
+		a.redefinitions = append(a.redefinitions, ClassRedefinition{
+			QualifiedName: name,
+			Definitions:   definitions,
+			Difference:    strings.Join(differences, "、"),
+		})
+	}
+
+	if len(merged) == 0 {
+		return classes
+	}
+	var result []*CppClass
+	for _, class := range classes {
+		if !merged[class] {
+			result = append(result, class)
+		}
+	}
+	return result
+}
+
+// definitionDifference 比较同名类的两处定义，返回有差异的方面，定义相同时返回 nil
+func definitionDifference(a, b *CppClass) []string {
+	var differences []string
+	if a.ClassKey != b.ClassKey || a.TemplateHeader() != b.TemplateHeader() {
+		differences = append(differences, "类关键字或模板形参不同")
+	}
+	// describe 把基类、成员变量和成员方法分别拼接为一个字符串，便于比较
+	describe := func(class *CppClass) (bases, members, methods string) {
+		var sb [3]strings.Builder
+		for _, baseClass := range class.BaseClasses {
+			sb[0].WriteString(baseClass.String() + "\n")
+		}
+		for _, member := range class.Members {
+			sb[1].WriteString(member.Access + " " + member.String() + "\n")
+		}
+		for _, method := range class.Methods {
+			sb[2].WriteString(method.Access + " " + method.String() + "\n")
+		}
+		return sb[0].String(), sb[1].String(), sb[2].String()
+	}
+	basesA, membersA, methodsA := describe(a)
+	basesB, membersB, methodsB := describe(b)
+	if basesA != basesB {
+		differences = append(differences, "基类不同")
+	}
+	if membersA != membersB {
+		differences = append(differences, "成员变量不同")
+	}
+	if methodsA != methodsB {
+		differences = append(differences, "成员方法不同")
+	}
+	return differences
+}
+
+// appendUnique 向列表追加尚未出现过的元素
+func appendUnique(list []string, items ...string) []string {
+	for _, item := range items {
+		found := false
+		for _, existing := range list {
+			if existing == item {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, item)
+		}
+	}
+	return list
+}
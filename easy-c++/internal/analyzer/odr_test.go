@@ -0,0 +1,77 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestCheckRedefinitions 测试跨文件的同名类定义检查: 相同的定义被合并，不同的定义都保留并报告冲突
+func TestCheckRedefinitions(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		// 同一个头文件的两份拷贝
+		"vendor1/point.h": "struct Point { int x; int y; };\n",
+		"vendor2/point.h": "struct Point { int x; int y; };\n",
+		"a/config.h": `class Base {};
+class Config : public Base {
+    int level;
+};
+`,
+		"b/config.h": `class Other {};
+
+class Config : public Other {
+    int level;
+    bool verbose;
+};
+`,
+		"a/impl.cpp": "namespace { struct Impl {}; }\n",
+		"b/impl.cpp": "namespace { struct Impl { int x; }; }\n",
+	}
+	var paths []string
+	for name, content := range files {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("创建目录失败: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+		paths = append(paths, path)
+	}
+
+	a := NewCppAnalyzer()
+	classes, err := a.AnalyzeFiles(paths)
+	if err != nil {
+		t.Fatalf("多文件分析失败: %v", err)
+	}
+
+	count := make(map[string]int)
+	for _, class := range classes {
+		count[class.QualifiedName]++
+		if class.Conflicting != (class.Name == "Config") {
+			t.Errorf("类 %s 的 Conflicting 应为 %v", class.QualifiedName, !class.Conflicting)
+		}
+	}
+	if count["Point"] != 1 || count["Config"] != 2 || count["Impl"] != 2 {
+		t.Errorf("相同的定义应合并，不同的定义应都保留，实际: %v", count)
+	}
+
+	redefinitions := make(map[string]ClassRedefinition)
+	for _, redefinition := range a.Redefinitions() {
+		redefinitions[redefinition.QualifiedName] = redefinition
+	}
+	if len(redefinitions) != 2 {
+		t.Errorf("期望 2 个重复定义的类，实际: %d", len(redefinitions))
+	}
+	if point := redefinitions["Point"]; point.Conflicting() || len(point.Definitions) != 2 {
+		t.Errorf("Point 的两处定义相同，不应报告冲突: %+v", point)
+	}
+	config := redefinitions["Config"]
+	if !config.Conflicting() || config.Difference != "基类不同、成员变量不同" {
+		t.Errorf("Config 的差异不正确: %q", config.Difference)
+	}
+	if locations := config.Locations(); len(locations) != 2 || locations[0] == locations[1] {
+		t.Errorf("应报告 Config 的两处定义位置，实际: %v", locations)
+	}
+}
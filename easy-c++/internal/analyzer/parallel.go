@@ -0,0 +1,115 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// SetJobs 设置并发解析文件的最大数量，n <= 0 时使用 CPU 核数
+// 并发只影响速度: 结果、诊断和失败文件的顺序与逐个解析时相同
+func (a *CppAnalyzer) SetJobs(n int) {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	a.jobs = n
+}
+
+// parallel 用最多 a.jobs 个 goroutine 对 0..n-1 调用 fn，全部完成后返回
+// fn 只能写入各自下标对应的结果，不能修改分析器的状态。
+// ctx 被取消时不再开始新的调用，等待进行中的调用结束后返回 ctx.Err()
+func (a *CppAnalyzer) parallel(ctx context.Context, n int, fn func(i int)) error {
+	workers := a.jobs
+	if workers > n {
+		workers = n
+	}
+	if workers <= 1 {
+		for i := 0; i < n; i++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			fn(i)
+		}
+		return ctx.Err()
+	}
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				fn(i)
+			}
+		}()
+	}
+send:
+	for i := 0; i < n; i++ {
+		select {
+		case indices <- i:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(indices)
+	wg.Wait()
+	return ctx.Err()
+}
+
+// recoverPanic 在 defer 中调用，把单个文件的解析中发生的 panic 转换为错误存入 *err
+// 这样一个文件触发的解析器缺陷只使该文件分析失败，不会在工作 goroutine 中使整个程序崩溃
+func recoverPanic(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("解析器内部错误: %v", r)
+	}
+}
+
+// parseFiles 并发解析文件，再按 paths 的顺序合并结果并为其中的类记录文件路径
+// 失败的文件记入诊断和返回的错误列表，设置了 SetFailFast 时在第一个失败的文件处停止。
+// ctx 被取消时返回 ctx.Err()
+func (a *CppAnalyzer) parseFiles(ctx context.Context, paths []string) ([]*CppClass, []*FileError, error) {
+	type outcome struct {
+		result parseResult
+		err    error
+	}
+	outcomes := make([]outcome, len(paths))
+	// 立即停止时，第一个失败的文件之后的文件不必再解析
+	var firstFailed atomic.Int64
+	firstFailed.Store(int64(len(paths)))
+	err := a.parallel(ctx, len(paths), func(i int) {
+		if a.failFast && int64(i) > firstFailed.Load() {
+			return
+		}
+		result, err := a.readFile(paths[i])
+		outcomes[i] = outcome{result, err}
+		if err != nil {
+			for {
+				current := firstFailed.Load()
+				if int64(i) >= current || firstFailed.CompareAndSwap(current, int64(i)) {
+					break
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var allClasses []*CppClass
+	var failed []*FileError
+	for i, path := range paths {
+		if err := outcomes[i].err; err != nil {
+			a.report(SeverityError, CodeFileRead, path, 0, 0, "%v", err)
+			failed = append(failed, &FileError{Path: path, Err: err})
+			if a.failFast {
+				break
+			}
+			continue
+		}
+		allClasses = append(allClasses, a.addFile(path, a.FileKind(path), outcomes[i].result)...)
+	}
+	return allClasses, failed, nil
+}
@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestParallelAnalysis 测试并发解析的结果与逐个解析相同，以及取消分析
+func TestParallelAnalysis(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"base.h": "#define EXPORT\nclass EXPORT Base { virtual void run(); };\n",
+	}
+	for i := 0; i < 40; i++ {
+		files[fmt.Sprintf("derived%02d.h", i)] = fmt.Sprintf(`#include "base.h"
+class Derived%02d : public Base {
+    void run() override;
+};
+void Derived%02d::stop() {}
+`, i, i)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+	}
+
+	type summary struct {
+		classes     []string
+		diagnostics Diagnostics
+	}
+	analyze := func(jobs int) summary {
+		a := NewCppAnalyzer()
+		a.SetJobs(jobs)
+		classes, err := a.AnalyzeProject(tempDir)
+		if err != nil {
+			t.Fatalf("项目分析失败: %v", err)
+		}
+		var s summary
+		for _, class := range classes {
+			s.classes = append(s.classes, fmt.Sprintf("%s %s:%d", class.QualifiedName, filepath.Base(class.FilePath), class.LineNumber))
+		}
+		s.diagnostics = a.Diagnostics()
+		return s
+	}
+	sequential := analyze(1)
+	if len(sequential.classes) != 41 || len(sequential.diagnostics) != 40 {
+		t.Fatalf("期望 41 个类和 40 条诊断，实际: %d, %d", len(sequential.classes), len(sequential.diagnostics))
+	}
+	for _, jobs := range []int{2, 8, 0} {
+		if parallel := analyze(jobs); !reflect.DeepEqual(parallel, sequential) {
+			t.Errorf("并发数为 %d 时结果与逐个解析不同", jobs)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	a := NewCppAnalyzer()
+	if _, err := a.AnalyzeProjectContext(ctx, tempDir); !errors.Is(err, context.Canceled) {
+		t.Errorf("取消后应返回 context.Canceled，实际: %v", err)
+	}
+	if _, err := a.AnalyzeFilesContext(ctx, []string{filepath.Join(tempDir, "base.h")}); !errors.Is(err, context.Canceled) {
+		t.Errorf("取消后应返回 context.Canceled，实际: %v", err)
+	}
+}
+
+// TestRecoverPanic 测试解析中的 panic 转换为该文件的错误
+func TestRecoverPanic(t *testing.T) {
+	parse := func() (err error) {
+		defer recoverPanic(&err)
+		panic("slice bounds out of range")
+	}
+	if err := parse(); err == nil || !strings.Contains(err.Error(), "slice bounds out of range") {
+		t.Errorf("panic 应转换为错误，实际: %v", err)
+	}
+}
@@ -0,0 +1,838 @@
+package analyzer
+
+import (
+	"strings"
+)
+
+// scopeKind 作用域种类
+type scopeKind int
+
+const (
+	scopeBlock     scopeKind = iota // 普通代码块，如 if/for 的语句块和初始化列表
+	scopeNamespace                  // 命名空间
+	scopeClass                      // 类体
+	scopeFunction                   // 函数体
+)
+
+// scope 解析过程中的一层作用域
+type scope struct {
+	kind  scopeKind
+	name  string    // 命名空间名；函数体为函数的限定名
+	class *CppClass // 类体对应的类
+}
+
+// parser 在词法单元序列上提取类定义
+type parser struct {
+	toks    []Token
+	pos     int
+	classes []*CppClass
+
+	scopes           []scope            // 外层作用域栈
+	inlineNamespaces map[string]bool    // 遇到的内联命名空间的限定名
+	definitions      []MethodDefinition // 命名空间作用域中的类体外成员函数定义
+}
+
+// newParser 创建解析器，预处理指令不参与类提取
+func newParser(tokens []Token) *parser {
+	toks := make([]Token, 0, len(tokens))
+	for _, t := range tokens {
+		if t.Kind != TokenDirective {
+			toks = append(toks, t)
+		}
+	}
+	return &parser{toks: toks, inlineNamespaces: make(map[string]bool)}
+}
+
+// parse 扫描整个词法单元序列并返回发现的类
+func (p *parser) parse() []*CppClass {
+	p.parseBlock(0)
+	return p.classes
+}
+
+// parseBlock 扫描词法单元，直到作用域栈的深度降到 depth 以下或序列结束
+// 类体由 parseClass 单独处理，函数体和其他代码块中的局部类在这里被发现
+func (p *parser) parseBlock(depth int) {
+	for p.pos < len(p.toks) {
+		switch {
+		case p.isClassHead():
+			p.parseClass(nil)
+		case p.at(0).Text == "template" && p.at(1).Text == "<":
+			inner, end := angleContents(p.toks, p.pos+1)
+			p.pos = end
+			if p.isClassHead() {
+				p.parseClass(parseTemplateParams(inner))
+			}
+		case p.at(0).Text == "namespace":
+			p.parseNamespace()
+		case p.at(0).Text == "{":
+			if p.enclosingScope() == nil {
+				p.recordDefinition(p.pos)
+			}
+			p.scopes = append(p.scopes, p.blockScope())
+			p.pos++
+		case p.at(0).Text == "}":
+			if len(p.scopes) > 0 {
+				p.scopes = p.scopes[:len(p.scopes)-1]
+			}
+			p.pos++
+			if len(p.scopes) < depth {
+				return
+			}
+		default:
+			p.pos++
+		}
+	}
+}
+
+// blockScope 判断当前位置的 { 是否为函数体的开始，返回对应的作用域
+// 函数体以函数的限定名命名，如 Foo::bar
+func (p *parser) blockScope() scope {
+	nameStart, open, _, ok := p.functionHead(p.pos)
+	if !ok {
+		return scope{kind: scopeBlock}
+	}
+	return scope{kind: scopeFunction, name: qualify(p.currentScopeName(), joinTokens(p.toks[nameStart:open]))}
+}
+
+// functionHead 从 body 处的 { 向前查找函数头，返回函数名的开始位置以及形参列表左右括号的位置
+// 函数名含类名限定，如 Foo::bar、Foo<T>::~Foo、Foo::operator==；形参列表之后的限定符和构造函数初始化列表被跳过
+func (p *parser) functionHead(body int) (nameStart, open, close int, ok bool) {
+	i := body - 1
+	for i >= 0 {
+		// 跳过形参列表之后的限定符，如 const、noexcept、override
+		for i >= 0 && isFunctionSuffix(p.toks[i].Text) {
+			i--
+		}
+		if i < 0 || (p.toks[i].Text != ")" && p.toks[i].Text != "}") {
+			return 0, 0, 0, false
+		}
+		o := matchingOpen(p.toks, i)
+		if o <= 0 {
+			return 0, 0, 0, false
+		}
+		if p.toks[o-1].Text == "noexcept" {
+			// noexcept(expr)
+			i = o - 2
+			continue
+		}
+		start := p.functionNameStart(o)
+		if start < 0 {
+			return 0, 0, 0, false
+		}
+		if start > 0 && (p.toks[start-1].Text == "," || p.toks[start-1].Text == ":") {
+			// 成员初始化列表 : a(x), b{y}，继续向前找构造函数的形参列表
+			i = start - 2
+			continue
+		}
+		if p.toks[o].Text != "(" || isStatementKeyword(p.toks[o-1].Text) {
+			return 0, 0, 0, false
+		}
+		return start, o, i, true
+	}
+	return 0, 0, 0, false
+}
+
+// functionNameStart 返回紧接在 open 处括号之前的(限定)函数名的开始位置，不是函数名时返回-1
+func (p *parser) functionNameStart(open int) int {
+	start := -1
+	// operator==、operator()、operator new[]、operator const char* 等
+	for i := open - 1; i >= 0 && i >= open-5; i-- {
+		if t := p.toks[i].Text; t == "operator" {
+			start = i
+			break
+		} else if t == ";" || t == "{" || t == "}" || t == "," {
+			break
+		}
+	}
+	if start < 0 {
+		if p.toks[open-1].Kind != TokenIdent {
+			return -1
+		}
+		start = open - 1
+		if start > 0 && p.toks[start-1].Text == "~" {
+			start--
+		}
+	}
+	// 类名限定，如 ns::Foo::、Stack<T>::
+	for start >= 2 && p.toks[start-1].Text == "::" {
+		prev := start - 2
+		if p.toks[prev].Text == ">" {
+			depth := 0
+			for ; prev >= 0; prev-- {
+				if p.toks[prev].Text == ">" {
+					depth++
+				} else if p.toks[prev].Text == "<" {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+			}
+			prev--
+		}
+		if prev < 0 || p.toks[prev].Kind != TokenIdent {
+			break
+		}
+		start = prev
+	}
+	return start
+}
+
+// recordDefinition 记录以限定名定义的成员函数，如 void Circle::draw() const {，body 为函数体 { 的位置
+func (p *parser) recordDefinition(body int) {
+	nameStart, open, close, ok := p.functionHead(body)
+	if !ok {
+		return
+	}
+	// 名字中最后一个 :: 之前为类名，没有类名限定的是普通函数
+	qual := -1
+	name := p.toks[nameStart:open]
+	for i, top := range topLevelMask(name) {
+		if top && name[i].Text == "::" {
+			qual = nameStart + i
+		}
+	}
+	if qual < 0 {
+		return
+	}
+	className := joinTokens(p.toks[nameStart:qual])
+
+	// 声明从上一条语句结束处开始，含模板头和返回类型；构造函数初始化列表不属于声明
+	start := nameStart
+	for start > 0 && p.toks[start-1].Text != ";" && p.toks[start-1].Text != "{" && p.toks[start-1].Text != "}" {
+		start--
+	}
+	end := close + 1
+	for end < body && p.toks[end].Text != ":" {
+		end++
+	}
+	decl := append(append([]Token{}, p.toks[start:nameStart]...), p.toks[qual+1:end]...)
+	parts := splitQualifiedName(TemplateName(className))
+	method, ok := parseMethod(decl, parts[len(parts)-1], "")
+	if !ok {
+		return
+	}
+	method.LineNumber = p.toks[nameStart].Line
+	p.definitions = append(p.definitions, MethodDefinition{
+		Class:  className,
+		Scope:  p.currentNamespace(),
+		Method: method,
+		Column: p.toks[nameStart].Column,
+	})
+}
+
+// isFunctionSuffix 判断是否为可以出现在函数形参列表和函数体之间的限定符
+func isFunctionSuffix(s string) bool {
+	switch s {
+	case "const", "volatile", "noexcept", "override", "final", "&", "&&", "mutable", "try":
+		return true
+	}
+	return false
+}
+
+// isStatementKeyword 判断是否为后面跟括号的语句关键字，如 if (x) {
+func isStatementKeyword(s string) bool {
+	switch s {
+	case "if", "for", "while", "switch", "catch", "return", "sizeof", "decltype", "noexcept", "alignas":
+		return true
+	}
+	return false
+}
+
+// matchingOpen 返回与toks[close]处闭括号匹配的开括号位置，不匹配时返回-1
+func matchingOpen(toks []Token, close int) int {
+	depth := 0
+	for i := close; i >= 0; i-- {
+		switch toks[i].Text {
+		case ")", "]", "}":
+			depth++
+		case "(", "[", "{":
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseNamespace 解析命名空间定义的开头并进入其作用域，当前位置为 namespace 关键字
+// 支持 inline namespace、匿名命名空间以及 C++17 的 a::b::c 嵌套形式；
+// 命名空间别名和 using namespace 被跳过
+func (p *parser) parseNamespace() {
+	inline := p.at(-1).Text == "inline"
+	p.pos = p.skipAttributes(p.pos + 1)
+
+	var parts []string
+	for p.at(0).Kind == TokenIdent || p.at(0).Text == "::" {
+		switch t := p.at(0); {
+		case t.Text == "inline":
+			inline = true
+		case t.Kind == TokenIdent:
+			parts = append(parts, t.Text)
+			if inline {
+				p.inlineNamespaces[qualify(p.currentNamespace(), strings.Join(parts, "::"))] = true
+				inline = false
+			}
+		}
+		p.pos++
+	}
+	p.pos = p.skipAttributes(p.pos)
+
+	if p.at(0).Text != "{" {
+		return
+	}
+	p.pos++
+	p.scopes = append(p.scopes, scope{kind: scopeNamespace, name: strings.Join(parts, "::")})
+}
+
+// currentNamespace 返回当前位置所在的命名空间
+func (p *parser) currentNamespace() string {
+	var parts []string
+	for _, s := range p.scopes {
+		if s.kind == scopeNamespace && s.name != "" {
+			parts = append(parts, s.name)
+		}
+	}
+	return strings.Join(parts, "::")
+}
+
+// currentScopeName 返回当前位置最内层具名作用域的限定名
+// 类体为类的限定名，函数体为 函数限定名()，否则为所在命名空间
+func (p *parser) currentScopeName() string {
+	for i := len(p.scopes) - 1; i >= 0; i-- {
+		switch s := p.scopes[i]; s.kind {
+		case scopeClass:
+			return s.class.QualifiedName
+		case scopeFunction:
+			return s.name + "()"
+		}
+	}
+	return p.currentNamespace()
+}
+
+// qualify 用作用域限定名字
+func qualify(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "::" + name
+}
+
+// at 返回当前位置偏移off处的词法单元，越界时返回 TokenEOF
+func (p *parser) at(off int) Token {
+	if i := p.pos + off; i >= 0 && i < len(p.toks) {
+		return p.toks[i]
+	}
+	return Token{Kind: TokenEOF}
+}
+
+// isClassHead 判断当前位置是否为类定义的开头: class-key [A::]Name[<args>] [final] (: | {)
+// enum class/enum struct、前置声明以及 struct stat buf 之类的详细类型说明符都不算
+func (p *parser) isClassHead() bool {
+	if !isClassKey(p.at(0).Text) || p.at(-1).Text == "enum" {
+		return false
+	}
+	i := p.skipAttributes(p.pos + 1)
+	if i >= len(p.toks) || p.toks[i].Kind != TokenIdent {
+		return false
+	}
+	i++
+	for i+1 < len(p.toks) && p.toks[i].Text == "::" && p.toks[i+1].Kind == TokenIdent {
+		i += 2
+	}
+	if i < len(p.toks) && p.toks[i].Text == "<" {
+		// 模板特化 class Foo<int>
+		i = matchingAngle(p.toks, i)
+	}
+	if i < len(p.toks) && p.toks[i].Text == "final" {
+		i++
+	}
+	return i < len(p.toks) && (p.toks[i].Text == "{" || p.toks[i].Text == ":")
+}
+
+// skipAttributes 跳过 [[...]]、alignas(...)、__declspec(...) 等属性，返回其后的位置
+// 未定义的导出宏也被跳过，如 class MYLIB_API Widget、class DECLSPEC_UUID("...") IFoo
+func (p *parser) skipAttributes(i int) int {
+	for i < len(p.toks) {
+		next := p.at(i + 1 - p.pos)
+		switch t := p.toks[i]; {
+		case t.Text == "[" && next.Text == "[":
+			i = matchingClose(p.toks, i)
+		case (t.Text == "alignas" || t.Text == "__declspec" || t.Text == "__attribute__") && next.Text == "(":
+			i = matchingClose(p.toks, i+1)
+		case isMacroName(t.Text) && next.Kind == TokenIdent && next.Text != "final":
+			i++
+		case isMacroName(t.Text) && next.Text == "(":
+			end := matchingClose(p.toks, i+1)
+			if end >= len(p.toks) || p.toks[end].Kind != TokenIdent {
+				return i
+			}
+			i = end
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// isMacroName 判断标识符是否具有宏名的形式: 至少两个字符，只含大写字母、数字和下划线
+func isMacroName(s string) bool {
+	if len(s) < 2 || isDigit(s[0]) {
+		return false
+	}
+	hasUpper := false
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= 'A' && c <= 'Z':
+			hasUpper = true
+		case c != '_' && !isDigit(c):
+			return false
+		}
+	}
+	return hasUpper
+}
+
+// isClassKey 判断是否为类关键字 class/struct/union
+func isClassKey(s string) bool {
+	return s == "class" || s == "struct" || s == "union"
+}
+
+// defaultAccess 返回类关键字对应的默认访问级别和继承方式
+func defaultAccess(classKey string) string {
+	if classKey == "class" {
+		return "private"
+	}
+	return "public"
+}
+
+// parseClass 解析一个类定义，当前位置为类关键字
+// params 为类模板的模板形参，非模板类为 nil
+// 嵌套类以外围类限定，局部类以所在函数限定，如 Outer::Node、f()::Local
+func (p *parser) parseClass(params []TemplateParam) *CppClass {
+	class := &CppClass{ClassKey: p.at(0).Text, LineNumber: p.at(0).Line, Column: p.at(0).Column, Namespace: p.currentNamespace()}
+	for _, s := range p.scopes {
+		if s.kind == scopeNamespace && s.name == "" {
+			class.AnonymousNamespace = true
+		}
+	}
+	if enclosing := p.enclosingScope(); enclosing != nil {
+		if enclosing.kind == scopeClass {
+			class.EnclosingClass = enclosing.class.QualifiedName
+		} else {
+			class.EnclosingFunction = enclosing.name
+		}
+	}
+	if len(params) > 0 {
+		class.TemplateParams = params
+	}
+	p.pos = p.skipAttributes(p.pos + 1)
+	name := p.at(0).Text
+	p.pos++
+	for p.at(0).Text == "::" {
+		// class Outer::Inner { 形式的限定类名
+		name += "::" + p.at(1).Text
+		p.pos += 2
+	}
+	parts := splitQualifiedName(name)
+	class.Name = parts[len(parts)-1]
+	if p.at(0).Text == "<" {
+		inner, end := angleContents(p.toks, p.pos)
+		class.SpecializationArgs = templateArgs(inner)
+		name += "<" + strings.Join(class.SpecializationArgs, ", ") + ">"
+		p.pos = end
+	}
+	class.QualifiedName = qualify(p.currentScopeName(), name)
+	if p.at(0).Text == "final" {
+		p.pos++
+	}
+
+	if p.at(0).Text == ":" {
+		p.pos++
+		start := p.pos
+		for p.pos < len(p.toks) && p.at(0).Text != "{" {
+			if isOpenBracket(p.at(0).Text) {
+				p.pos = matchingClose(p.toks, p.pos)
+				continue
+			}
+			p.pos++
+		}
+		class.BaseClasses = parseBaseClause(p.toks[start:p.pos], defaultAccess(class.ClassKey))
+	}
+
+	p.pos++ // 跳过 {
+	p.classes = append(p.classes, class)
+	p.scopes = append(p.scopes, scope{kind: scopeClass, class: class})
+	p.parseClassBody(class)
+	p.scopes = p.scopes[:len(p.scopes)-1]
+	return class
+}
+
+// enclosingScope 返回最内层的类体或函数体作用域，位于命名空间作用域时返回 nil
+func (p *parser) enclosingScope() *scope {
+	for i := len(p.scopes) - 1; i >= 0; i-- {
+		if p.scopes[i].kind == scopeClass || p.scopes[i].kind == scopeFunction {
+			return &p.scopes[i]
+		}
+	}
+	return nil
+}
+
+// parseNestedClass 解析类体中的嵌套类定义，struct Node {...} head; 形式同时声明的成员加入外围类
+func (p *parser) parseNestedClass(outer *CppClass, params []TemplateParam, access string) {
+	nested := p.parseClass(params)
+	decl, _ := p.readMemberDeclaration()
+	if len(decl) > 0 {
+		typeTok := Token{Kind: TokenIdent, Text: nested.Name, Line: decl[0].Line, Column: decl[0].Column}
+		addMemberDeclaration(outer, append([]Token{typeTok}, decl...), access)
+	}
+}
+
+// parseFunctionBody 扫描函数体中的局部类直到函数体结束，当前位置为函数体的 {
+func (p *parser) parseFunctionBody(name string) {
+	p.scopes = append(p.scopes, scope{kind: scopeFunction, name: qualify(p.currentScopeName(), name)})
+	p.pos++
+	p.parseBlock(len(p.scopes))
+}
+
+// parseClassBody 逐条解析类体中的成员声明，直到匹配的 }
+func (p *parser) parseClassBody(class *CppClass) {
+	access := defaultAccess(class.ClassKey)
+	for p.pos < len(p.toks) {
+		switch {
+		case p.at(0).Text == "}":
+			p.pos++
+			return
+		case isAccessKeyword(p.at(0).Text) && p.at(1).Text == ":":
+			access = p.at(0).Text
+			p.pos += 2
+			continue
+		case isAccessKeyword(p.at(0).Text) && p.at(1).Kind == TokenIdent && p.at(2).Text == ":":
+			// Qt 的 public slots: 等
+			access = p.at(0).Text
+			p.pos += 3
+			continue
+		case (p.at(0).Text == "signals" || p.at(0).Text == "Q_SIGNALS") && p.at(1).Text == ":":
+			access = "public"
+			p.pos += 2
+			continue
+		case p.isClassHead():
+			p.parseNestedClass(class, nil, access)
+			continue
+		case p.at(0).Text == "template" && p.at(1).Text == "<":
+			inner, end := angleContents(p.toks, p.pos+1)
+			save := p.pos
+			p.pos = end
+			if p.isClassHead() {
+				p.parseNestedClass(class, parseTemplateParams(inner), access)
+				continue
+			}
+			p.pos = save
+		}
+		decl, body := p.readMemberDeclaration()
+		methods := len(class.Methods)
+		addMemberDeclaration(class, decl, access)
+		if body && len(class.Methods) > methods {
+			// 在类体内定义的方法，定义位置即声明位置
+			class.Methods[methods].DefinitionLine = class.Methods[methods].LineNumber
+		}
+	}
+}
+
+// readMemberDeclaration 读取一条成员声明的词法单元，body 表示声明带有函数体
+// 函数体中只提取局部类；初始化器的花括号内容保留在声明中
+func (p *parser) readMemberDeclaration() (decl []Token, body bool) {
+	for p.pos < len(p.toks) {
+		t := p.at(0)
+		switch {
+		case t.Text == ";":
+			p.pos++
+			return decl, false
+		case t.Text == "}":
+			return decl, false
+		case t.Text == ":" && isFunctionDeclarator(decl):
+			p.pos = p.skipMemInitializers(p.pos + 1)
+		case t.Text == "{" && isFunctionDeclarator(decl):
+			name := ""
+			if method, ok := parseMethod(decl, "", ""); ok {
+				name = method.Name
+			}
+			p.parseFunctionBody(name)
+			return decl, true
+		case isOpenBracket(t.Text):
+			end := matchingClose(p.toks, p.pos)
+			decl = append(decl, p.toks[p.pos:end]...)
+			p.pos = end
+		default:
+			decl = append(decl, t)
+			p.pos++
+		}
+	}
+	return decl, false
+}
+
+// skipMemInitializers 跳过构造函数初始化列表 : a(x), b{y}，返回函数体 { 的位置
+func (p *parser) skipMemInitializers(i int) int {
+	for i < len(p.toks) {
+		switch t := p.toks[i]; {
+		case t.Text == ";":
+			return i
+		case t.Text == "{" && (p.toks[i-1].Kind == TokenIdent || p.toks[i-1].Text == ">"):
+			// 成员的花括号初始化 b{y}
+			i = matchingClose(p.toks, i)
+		case t.Text == "{":
+			return i
+		case t.Text == "(" || t.Text == "[":
+			i = matchingClose(p.toks, i)
+		default:
+			i++
+		}
+	}
+	return i
+}
+
+// addMemberDeclaration 将一条成员声明归类为成员变量或成员方法，access 为所在的访问级别
+func addMemberDeclaration(class *CppClass, decl []Token, access string) {
+	if len(decl) == 0 {
+		return
+	}
+	switch decl[0].Text {
+	case "friend", "using", "typedef", "static_assert",
+		"class", "struct", "union", "enum":
+		return
+	case "template":
+		// 成员函数模板保留，成员类模板、变量模板和别名模板跳过
+		if len(decl) < 2 || decl[1].Text != "<" {
+			return
+		}
+		_, end := angleContents(decl, 1)
+		if !isFunctionDeclarator(decl[end:]) {
+			return
+		}
+	}
+
+	if isFunctionDeclarator(decl) {
+		if method, ok := parseMethod(decl, class.Name, access); ok {
+			class.Methods = append(class.Methods, method)
+		}
+		return
+	}
+
+	class.Members = append(class.Members, parseMembers(decl, access)...)
+}
+
+// declaratorNameIndex 返回声明符中名字的位置
+func declaratorNameIndex(declarator []Token) int {
+	for j, t := range declarator {
+		if t.Text == "=" || t.Text == "[" || t.Text == "{" || t.Text == ":" {
+			return j - 1
+		}
+	}
+	return len(declarator) - 1
+}
+
+// parseBaseClause 解析基类子句，defaultAccess 为未写明继承方式时的默认访问级别
+func parseBaseClause(toks []Token, defaultAccess string) []BaseSpecifier {
+	var bases []BaseSpecifier
+	for _, spec := range splitTopLevel(toks, ",") {
+		base := BaseSpecifier{Access: defaultAccess}
+		var name []Token
+		for _, t := range spec {
+			switch t.Text {
+			case "public", "protected", "private":
+				base.Access = t.Text
+			case "virtual":
+				base.Virtual = true
+			case "...":
+				base.Variadic = true
+			case "typename":
+			default:
+				name = append(name, t)
+			}
+		}
+		if len(name) == 0 {
+			continue
+		}
+
+		// 拆出最后一组模板实参，如 ns::Base<Derived>；A<int>::Nested 中的实参属于名字本身
+		for i := 0; i < len(name); i++ {
+			if name[i].Text != "<" {
+				continue
+			}
+			inner, end := angleContents(name, i)
+			if end >= len(name) {
+				base.TemplateArgs = templateArgs(inner)
+				name = name[:i]
+				break
+			}
+			i = end - 1
+		}
+		base.Name = joinTokens(name)
+		bases = append(bases, base)
+	}
+	return bases
+}
+
+// isAccessKeyword 判断是否为访问修饰符 public/protected/private
+func isAccessKeyword(s string) bool {
+	return s == "public" || s == "protected" || s == "private"
+}
+
+// isFunctionDeclarator 判断声明是否声明了一个函数
+func isFunctionDeclarator(decl []Token) bool {
+	if findTopLevel(decl, "operator") >= 0 {
+		return true
+	}
+	paren := findParamParen(decl)
+	if paren <= 0 {
+		return false
+	}
+	for _, t := range decl[:paren] {
+		if t.Text == "=" {
+			return false
+		}
+	}
+	// void (*callback)(int) 之类的函数指针成员
+	prev := decl[paren-1]
+	return prev.Kind == TokenIdent && !isTypeKeyword(prev.Text)
+}
+
+// stripSpecifiers 去掉声明开头的说明符，保留类型部分
+func stripSpecifiers(toks []Token) []Token {
+	var result []Token
+	for _, t := range toks {
+		switch t.Text {
+		case "virtual", "static", "inline", "explicit", "constexpr", "consteval",
+			"friend", "mutable", "extern", "thread_local":
+			continue
+		}
+		result = append(result, t)
+	}
+	return result
+}
+
+// isTypeKeyword 判断标识符是否为内置类型关键字
+func isTypeKeyword(s string) bool {
+	switch s {
+	case "void", "bool", "char", "wchar_t", "char8_t", "char16_t", "char32_t",
+		"short", "int", "long", "float", "double", "signed", "unsigned", "auto":
+		return true
+	}
+	return false
+}
+
+// isOpenBracket 判断是否为开括号
+func isOpenBracket(s string) bool {
+	return s == "(" || s == "[" || s == "{"
+}
+
+// matchingClose 返回与toks[open]处开括号匹配的闭括号之后的位置
+// 不匹配时返回序列末尾
+func matchingClose(toks []Token, open int) int {
+	depth := 0
+	for i := open; i < len(toks); i++ {
+		switch toks[i].Text {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(toks)
+}
+
+// bracketContents 返回toks[open]处的括号内的词法单元以及匹配的闭括号之后的位置
+// 缺少闭括号时(如文件在声明中间结束)内容延伸到序列末尾
+func bracketContents(toks []Token, open int) ([]Token, int) {
+	end := matchingClose(toks, open)
+	if matchingOpen(toks, end-1) != open {
+		return toks[open+1:], end
+	}
+	return toks[open+1 : end-1], end
+}
+
+// findTopLevel 返回text在括号和尖括号之外第一次出现的位置，未找到时返回-1
+func findTopLevel(toks []Token, text string) int {
+	for i, top := range topLevelMask(toks) {
+		if top && toks[i].Text == text {
+			return i
+		}
+	}
+	return -1
+}
+
+// topLevelMask 标记每个词法单元是否位于括号和尖括号之外
+func topLevelMask(toks []Token) []bool {
+	mask := make([]bool, len(toks))
+	depth, angle := 0, 0
+	for i, t := range toks {
+		mask[i] = depth == 0 && angle == 0
+		switch t.Text {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+		case "<":
+			if depth == 0 && i > 0 && toks[i-1].Kind == TokenIdent {
+				angle++
+			}
+		case ">":
+			if depth == 0 && angle > 0 {
+				angle--
+			}
+		case ">>":
+			if depth == 0 && angle > 0 {
+				angle = max(angle-2, 0)
+			}
+		}
+	}
+	return mask
+}
+
+// splitTopLevel 按括号和尖括号之外的分隔符切分词法单元序列
+func splitTopLevel(toks []Token, sep string) [][]Token {
+	var parts [][]Token
+	for {
+		i := findTopLevel(toks, sep)
+		if i < 0 {
+			break
+		}
+		parts = append(parts, toks[:i])
+		toks = toks[i+1:]
+	}
+	if len(toks) > 0 {
+		parts = append(parts, toks)
+	}
+	return parts
+}
+
+// joinTokens 将词法单元拼接为可读的源代码文本
+func joinTokens(toks []Token) string {
+	var sb strings.Builder
+	for i, t := range toks {
+		if i > 0 && needsSpace(toks[i-1], t) {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(t.Text)
+	}
+	return sb.String()
+}
+
+// needsSpace 判断两个相邻词法单元之间是否需要空格
+func needsSpace(prev, next Token) bool {
+	switch {
+	case prev.Text == ",":
+		return true
+	case prev.Kind != TokenPunct && next.Kind != TokenPunct:
+		return true
+	case prev.Text == "=" || next.Text == "=":
+		return true
+	case (prev.Text == "*" || prev.Text == "&" || prev.Text == "&&" || prev.Text == ">") && next.Kind == TokenIdent:
+		// const char* const name, std::vector<int> items
+		return true
+	}
+	return false
+}
@@ -0,0 +1,525 @@
+package analyzer
+
+import (
+	"testing"
+)
+
+// TestParser_MultiLineDeclarations 测试跨行类头和单行多个声明
+func TestParser_MultiLineDeclarations(t *testing.T) {
+	src := `
+class Widget
+    : public Base,
+      protected Mixin<int, char>
+{
+public:
+    int x; int y;
+    const char* label = "}";
+    std::vector<int> items{1, 2};
+    void draw() const { if (x) { y = '{'; } }
+    Widget() : x(0), y{1} {}
+    std::string
+    name() const;
+};
+
+class Other { int v; }; class Third : Widget {};
+`
+	classes := NewCppAnalyzer().analyzeSource(src)
+	if len(classes) != 3 {
+		t.Fatalf("期望找到3个类，实际找到%d个", len(classes))
+	}
+
+	widget := classes[0]
+	if widget.Name != "Widget" || widget.LineNumber != 2 {
+		t.Errorf("Widget 的名称或行号不正确: %s:%d", widget.Name, widget.LineNumber)
+	}
+	if !sliceEqual(baseNames(widget), []string{"Base", "Mixin<int, char>"}) {
+		t.Errorf("Widget 的基类不正确: %v", widget.BaseClasses)
+	}
+	expectedMembers := []string{"int x", "int y", `const char* label = "}"`, "std::vector<int> items{1, 2}"}
+	if !sliceEqual(memberDecls(widget), expectedMembers) {
+		t.Errorf("Widget 的成员变量不正确，期望: %v，实际: %v", expectedMembers, memberDecls(widget))
+	}
+	expectedMethods := []string{"void draw() const", "Widget()", "std::string name() const"}
+	if !sliceEqual(methodDecls(widget), expectedMethods) {
+		t.Errorf("Widget 的成员方法不正确，期望: %v，实际: %v", expectedMethods, methodDecls(widget))
+	}
+
+	if classes[1].Name != "Other" || classes[2].Name != "Third" {
+		t.Errorf("同一行的类未正确识别: %s, %s", classes[1].Name, classes[2].Name)
+	}
+	if !sliceEqual(baseNames(classes[2]), []string{"Widget"}) {
+		t.Errorf("Third 的基类不正确: %v", classes[2].BaseClasses)
+	}
+}
+
+// TestParser_Members 测试成员变量的访问级别、存储类别和完整声明符
+func TestParser_Members(t *testing.T) {
+	src := `
+class Config {
+    int implicitPrivate;
+public:
+    static constexpr int kMax = 10;
+    const char* const name;
+    const char* label;
+    int* ptr, value, arr[4][N];
+    std::map<std::string, int*> table;
+    const Config& parent;
+protected:
+    mutable std::mutex lock;
+    unsigned flags : 3, mode : 2 = 1;
+    static inline const std::string kDefault{"x"};
+public slots:
+    void onClick();
+    bool visible = true;
+};
+`
+	classes := NewCppAnalyzer().analyzeSource(src)
+	if len(classes) != 1 {
+		t.Fatalf("期望找到1个类，实际找到%d个", len(classes))
+	}
+
+	expected := []struct {
+		decl, access  string
+		static, konst bool
+	}{
+		{"int implicitPrivate", "private", false, false},
+		{"static constexpr int kMax = 10", "public", true, true},
+		{"const char* const name", "public", false, true},
+		{"const char* label", "public", false, false},
+		{"int* ptr", "public", false, false},
+		{"int value", "public", false, false},
+		{"int arr[4][N]", "public", false, false},
+		{"std::map<std::string, int*> table", "public", false, false},
+		{"const Config& parent", "public", false, false},
+		{"mutable std::mutex lock", "protected", false, false},
+		{"unsigned flags : 3", "protected", false, false},
+		{"unsigned mode : 2 = 1", "protected", false, false},
+		{`static const std::string kDefault{"x"}`, "protected", true, true},
+		{"bool visible = true", "public", false, false},
+	}
+	members := classes[0].Members
+	if len(members) != len(expected) {
+		t.Fatalf("期望找到%d个成员变量，实际找到%d个: %v", len(expected), len(members), memberDecls(classes[0]))
+	}
+	for i, e := range expected {
+		m := members[i]
+		if m.String() != e.decl || m.Access != e.access || m.Static != e.static || m.Const != e.konst {
+			t.Errorf("第%d个成员不正确，期望: %s/%s/static=%v/const=%v，实际: %s/%s/static=%v/const=%v",
+				i+1, e.decl, e.access, e.static, e.konst, m.String(), m.Access, m.Static, m.Const)
+		}
+	}
+
+	if arr := members[6]; !sliceEqual(arr.ArrayExtents, []string{"4", "N"}) {
+		t.Errorf("数组维度不正确: %q", arr.ArrayExtents)
+	}
+	if mode := members[11]; mode.BitWidth != "2" || mode.Default != "1" {
+		t.Errorf("位域解析不正确: %+v", mode)
+	}
+	if !members[9].Mutable || !members[1].Constexpr {
+		t.Errorf("mutable 或 constexpr 标记不正确")
+	}
+	if len(classes[0].Methods) != 1 {
+		t.Errorf("期望找到1个成员方法，实际: %v", classes[0].Methods)
+	}
+}
+
+// memberDecls 返回类所有成员变量的声明形式
+func memberDecls(class *CppClass) []string {
+	var decls []string
+	for _, m := range class.Members {
+		decls = append(decls, m.String())
+	}
+	return decls
+}
+
+// TestParser_Methods 测试成员方法的形参、限定符和种类
+func TestParser_Methods(t *testing.T) {
+	src := `
+class Shape : public Base {
+public:
+    Shape() = default;
+    explicit Shape(const std::string& name, int sides = 3);
+    Shape(const Shape&) = delete;
+    virtual ~Shape() noexcept;
+    virtual double area() const = 0;
+    void draw(int x, int y) const override final;
+    void draw(double scale) &&;
+    [[nodiscard]] static Shape* create(void (*callback)(int), ...);
+    bool operator==(const Shape& other) const noexcept;
+    Shape& operator=(Shape&&) noexcept(false);
+    int operator()(int a, int b = 0);
+    explicit operator bool() const;
+    auto size() const -> std::size_t { return 0; }
+    template<typename T> void visit(T&& visitor);
+protected:
+    virtual void onResize(int values[], unsigned long) {}
+private:
+    std::function<void(int)> callback;
+};
+`
+	classes := NewCppAnalyzer().analyzeSource(src)
+	if len(classes) != 1 {
+		t.Fatalf("期望找到1个类，实际找到%d个", len(classes))
+	}
+
+	expected := []struct {
+		decl, signature, access string
+		kind                    MethodKind
+	}{
+		{"Shape() = default", "Shape()", "public", MethodConstructor},
+		{`explicit Shape(const std::string& name, int sides = 3)`, "Shape(const std::string&, int)", "public", MethodConstructor},
+		{"Shape(const Shape&) = delete", "Shape(const Shape&)", "public", MethodConstructor},
+		{"virtual ~Shape() noexcept", "~Shape()", "public", MethodDestructor},
+		{"virtual double area() const = 0", "area() const", "public", MethodNormal},
+		{"void draw(int x, int y) const override final", "draw(int, int) const", "public", MethodNormal},
+		{"void draw(double scale) &&", "draw(double) &&", "public", MethodNormal},
+		{"static Shape* create(void(*callback)(int), ...)", "create(void(*)(int), ...)", "public", MethodNormal},
+		{"bool operator==(const Shape& other) const noexcept", "operator==(const Shape&) const", "public", MethodOperator},
+		{"Shape& operator=(Shape&&)", "operator=(Shape&&)", "public", MethodOperator},
+		{"int operator()(int a, int b = 0)", "operator()(int, int)", "public", MethodOperator},
+		{"explicit operator bool() const", "operator bool() const", "public", MethodConversion},
+		{"std::size_t size() const", "size() const", "public", MethodNormal},
+		{"template<typename T> void visit(T&& visitor)", "visit(T&&)", "public", MethodNormal},
+		{"virtual void onResize(int values[], unsigned long)", "onResize(int[], unsigned long)", "protected", MethodNormal},
+	}
+	methods := classes[0].Methods
+	if len(methods) != len(expected) {
+		t.Fatalf("期望找到%d个成员方法，实际找到%d个: %v", len(expected), len(methods), methodDecls(classes[0]))
+	}
+	for i, e := range expected {
+		m := methods[i]
+		if m.String() != e.decl || m.Signature() != e.signature || m.Access != e.access || m.Kind != e.kind {
+			t.Errorf("第%d个方法不正确，期望: %s | %s | %s | %s，实际: %s | %s | %s | %s",
+				i+1, e.decl, e.signature, e.access, e.kind, m.String(), m.Signature(), m.Access, m.Kind)
+		}
+	}
+
+	if area := methods[4]; !area.Virtual || !area.Pure || !area.Const {
+		t.Errorf("area 应为 const 纯虚函数: %+v", area)
+	}
+	if draw := methods[5]; !draw.Override || !draw.Final || draw.Virtual {
+		t.Errorf("draw 的 override/final 标记不正确: %+v", draw)
+	}
+	if ctor := methods[1]; ctor.Params[1].Default != "3" || ctor.Params[0].Name != "name" {
+		t.Errorf("构造函数形参解析不正确: %+v", ctor.Params)
+	}
+	if assign := methods[9]; assign.Noexcept {
+		t.Errorf("noexcept(false) 不应视为 noexcept")
+	}
+	if len(classes[0].Members) != 1 || classes[0].Members[0].Name != "callback" {
+		t.Errorf("std::function 成员不应被识别为方法: %v", memberDecls(classes[0]))
+	}
+}
+
+// methodDecls 返回类所有成员方法的声明形式
+func methodDecls(class *CppClass) []string {
+	var decls []string
+	for _, m := range class.Methods {
+		decls = append(decls, m.String())
+	}
+	return decls
+}
+
+// TestParser_ClassKeys 测试 struct/union 的默认访问级别以及不应识别为类的 class 关键字
+func TestParser_ClassKeys(t *testing.T) {
+	src := `
+enum class Color { Red, Green };
+enum struct Mode : unsigned char { A, B };
+class Forward;
+struct stat info;
+template<class T> class Holder;
+struct Point : Base {
+    int x, y;
+private:
+    int hidden;
+};
+union Value {
+    int i;
+    float f;
+};
+class Widget : Point {
+    friend class Factory;
+    int secret;
+};
+struct alignas(16) [[nodiscard]] Aligned final {};
+void f(class Param* p, struct Point pt);
+`
+	classes := NewCppAnalyzer().analyzeSource(src)
+
+	expected := []struct{ name, key string }{
+		{"Point", "struct"},
+		{"Value", "union"},
+		{"Widget", "class"},
+		{"Aligned", "struct"},
+	}
+	if len(classes) != len(expected) {
+		for _, c := range classes {
+			t.Logf("  %s %s", c.ClassKey, c.QualifiedName)
+		}
+		t.Fatalf("期望找到%d个类，实际找到%d个", len(expected), len(classes))
+	}
+	for i, e := range expected {
+		if classes[i].Name != e.name || classes[i].ClassKey != e.key {
+			t.Errorf("第%d个类不正确，期望: %s %s，实际: %s %s", i+1, e.key, e.name, classes[i].ClassKey, classes[i].Name)
+		}
+	}
+
+	point := classes[0]
+	if point.BaseClasses[0].Access != "public" {
+		t.Errorf("struct 的默认继承方式应为 public，实际: %s", point.BaseClasses[0].Access)
+	}
+	var access []string
+	for _, m := range point.Members {
+		access = append(access, m.Access)
+	}
+	if !sliceEqual(access, []string{"public", "public", "private"}) {
+		t.Errorf("struct 成员的访问级别不正确: %v", access)
+	}
+	if classes[1].Members[0].Access != "public" {
+		t.Errorf("union 成员的默认访问级别应为 public")
+	}
+	widget := classes[2]
+	if widget.BaseClasses[0].Access != "private" || widget.Members[0].Access != "private" {
+		t.Errorf("class 的默认访问级别应为 private: %v %v", widget.BaseClasses, memberDecls(widget))
+	}
+}
+
+// TestParser_NestedClasses 测试嵌套类和局部类的提取与限定名
+func TestParser_NestedClasses(t *testing.T) {
+	src := `
+namespace ui {
+class Outer {
+public:
+    struct Node {
+        struct Leaf : Node {};
+    } head;
+    void visit() {
+        class Visitor : public Node {};
+    }
+private:
+    template<typename T> class Cache {};
+    Outer() : value(0), items{1} { struct Init {}; }
+    int value;
+};
+}
+class Impl : public ui::Outer::Node {};
+void Impl::run() const {
+    if (ready) { struct Step {}; }
+}
+`
+	a := NewCppAnalyzer()
+	classes := a.analyzeSource(src)
+	a.qualifyBaseClasses(classes)
+
+	expected := []struct{ qualified, enclosingClass, enclosingFunction string }{
+		{"ui::Outer", "", ""},
+		{"ui::Outer::Node", "ui::Outer", ""},
+		{"ui::Outer::Node::Leaf", "ui::Outer::Node", ""},
+		{"ui::Outer::visit()::Visitor", "", "ui::Outer::visit"},
+		{"ui::Outer::Cache", "ui::Outer", ""},
+		{"ui::Outer::Outer()::Init", "", "ui::Outer::Outer"},
+		{"Impl", "", ""},
+		{"Impl::run()::Step", "", "Impl::run"},
+	}
+	if len(classes) != len(expected) {
+		for _, c := range classes {
+			t.Logf("  %s", c.QualifiedName)
+		}
+		t.Fatalf("期望找到%d个类，实际找到%d个", len(expected), len(classes))
+	}
+	for i, e := range expected {
+		c := classes[i]
+		if c.QualifiedName != e.qualified || c.EnclosingClass != e.enclosingClass || c.EnclosingFunction != e.enclosingFunction {
+			t.Errorf("第%d个类不正确，期望: %s/%s/%s，实际: %s/%s/%s", i+1,
+				e.qualified, e.enclosingClass, e.enclosingFunction, c.QualifiedName, c.EnclosingClass, c.EnclosingFunction)
+		}
+		if c.Namespace != "ui" && i < 6 {
+			t.Errorf("%s 的命名空间应为 ui，实际: %s", c.QualifiedName, c.Namespace)
+		}
+	}
+
+	outer := classes[0]
+	if !sliceEqual(memberDecls(outer), []string{"Node head", "int value"}) {
+		t.Errorf("Outer 的成员变量不正确: %v", memberDecls(outer))
+	}
+	if len(outer.Methods) != 2 {
+		t.Errorf("Outer 应有2个成员方法，实际: %v", methodDecls(outer))
+	}
+	if got := baseNames(classes[2]); !sliceEqual(got, []string{"ui::Outer::Node"}) {
+		t.Errorf("Leaf 的基类应解析为外围类: %v", got)
+	}
+	if got := baseNames(classes[3]); !sliceEqual(got, []string{"ui::Outer::Node"}) {
+		t.Errorf("局部类 Visitor 的基类应解析为 ui::Outer::Node: %v", got)
+	}
+	if got := baseNames(classes[6]); !sliceEqual(got, []string{"ui::Outer::Node"}) {
+		t.Errorf("Impl 的基类应解析为嵌套类: %v", got)
+	}
+	var nested []string
+	for _, c := range FindNestedClasses(classes, outer) {
+		nested = append(nested, c.Name)
+	}
+	if !sliceEqual(nested, []string{"Node", "Cache"}) {
+		t.Errorf("Outer 的嵌套类不正确: %v", nested)
+	}
+}
+
+// TestParser_Namespaces 测试命名空间跟踪和限定类名
+func TestParser_Namespaces(t *testing.T) {
+	src := `
+class Global {};
+namespace ui {
+    class Widget {};
+    namespace detail { class Impl {}; }
+}
+namespace core::gfx { class Widget {}; }
+inline namespace v1 { class Versioned {}; }
+namespace lib { inline namespace v2 { class Api {}; } }
+namespace { class Hidden {}; }
+namespace alias = ui::detail;
+using namespace ui;
+void f() { int x{1}; }
+namespace ui { class Button : public Widget {}; }
+class ui::Widget::Part {};
+`
+	p := newParser(Tokenize(src))
+	classes := p.parse()
+
+	expected := []struct{ name, qualified, namespace string }{
+		{"Global", "Global", ""},
+		{"Widget", "ui::Widget", "ui"},
+		{"Impl", "ui::detail::Impl", "ui::detail"},
+		{"Widget", "core::gfx::Widget", "core::gfx"},
+		{"Versioned", "v1::Versioned", "v1"},
+		{"Api", "lib::v2::Api", "lib::v2"},
+		{"Hidden", "Hidden", ""},
+		{"Button", "ui::Button", "ui"},
+		{"Part", "ui::Widget::Part", ""},
+	}
+	if len(classes) != len(expected) {
+		t.Fatalf("期望找到%d个类，实际找到%d个", len(expected), len(classes))
+	}
+	for i, e := range expected {
+		c := classes[i]
+		if c.Name != e.name || c.QualifiedName != e.qualified || c.Namespace != e.namespace {
+			t.Errorf("第%d个类不正确，期望: %s/%s/%s，实际: %s/%s/%s",
+				i+1, e.name, e.qualified, e.namespace, c.Name, c.QualifiedName, c.Namespace)
+		}
+	}
+	if !p.inlineNamespaces["v1"] || !p.inlineNamespaces["lib::v2"] || len(p.inlineNamespaces) != 2 {
+		t.Errorf("内联命名空间识别不正确: %v", p.inlineNamespaces)
+	}
+}
+
+// TestParser_TruncatedNamespace 测试文件在命名空间名之后结束时解析能够终止
+func TestParser_TruncatedNamespace(t *testing.T) {
+	for _, src := range []string{
+		"class A {};\nnamespace v1",
+		"namespace a::b::",
+		"inline namespace",
+		"namespace [[deprecated]] lib",
+	} {
+		classes := newParser(Tokenize(src)).parse()
+		if len(classes) > 1 {
+			t.Errorf("%q: 期望最多找到1个类，实际找到%d个", src, len(classes))
+		}
+	}
+}
+
+// TestParser_TruncatedMethods 测试文件在方法声明中间结束时不会出错，不完整的声明不作为方法
+func TestParser_TruncatedMethods(t *testing.T) {
+	src := `class Shape {
+public:
+    explicit(true) Shape(int sides, double (*scale)(double)) noexcept(false);
+    virtual ~Shape() = default;
+    [[nodiscard]] virtual double area(const Point& origin = {0, 0}) const = 0;
+    auto operator()(int x) -> decltype(x + 1);
+};
+`
+	for i := 0; i <= len(src); i++ {
+		classes := newParser(Tokenize(src[:i])).parse()
+		for _, class := range classes {
+			for _, method := range class.Methods {
+				if method.Name == "" {
+					t.Errorf("截断在第%d个字节时得到无名方法: %+v", i, method)
+				}
+			}
+		}
+	}
+
+	classes := newParser(Tokenize("class Shape {\npublic:\n    int sides;\n    virtual ~Shape(")).parse()
+	if len(classes) != 1 || len(classes[0].Methods) != 0 || len(classes[0].Members) != 1 {
+		t.Errorf("不完整的析构函数声明不应作为方法: %+v", classes)
+	}
+}
+
+// TestParser_Templates 测试类模板、特化和模板基类
+func TestParser_Templates(t *testing.T) {
+	src := `
+template<typename T, int N = 4, template<class> class Alloc = std::allocator, typename... Ts>
+class Buffer : public Storage<T, N>, public Ts... {};
+
+template<class Derived>
+class Counter {};
+
+class Widget : public Counter<Widget> {};
+
+template<typename T>
+class Node final : public Counter<Node<T>> {};
+
+template<>
+class Buffer<bool> {};
+
+template<typename T>
+class Buffer<T*> : public Buffer<T> {};
+
+template<class T> class Fwd;
+template<class T> void f(T) { class Local {}; }
+`
+	classes := NewCppAnalyzer().analyzeSource(src)
+	if len(classes) != 7 {
+		for _, c := range classes {
+			t.Logf("  %s", c.QualifiedName)
+		}
+		t.Fatalf("期望找到7个类，实际找到%d个", len(classes))
+	}
+
+	buffer := classes[0]
+	var params []string
+	for _, param := range buffer.TemplateParams {
+		params = append(params, param.String())
+	}
+	expectedParams := []string{"typename T", "int N = 4", "template<class> class Alloc = std::allocator", "typename... Ts"}
+	if !sliceEqual(params, expectedParams) {
+		t.Errorf("Buffer 的模板形参不正确，期望: %q，实际: %q", expectedParams, params)
+	}
+	if !sliceEqual(baseNames(buffer), []string{"Storage<T, N>", "Ts..."}) {
+		t.Errorf("Buffer 的基类不正确: %v", buffer.BaseClasses)
+	}
+	if !buffer.BaseClasses[1].Variadic || !buffer.IsTemplateParam(buffer.BaseClasses[1].Name) {
+		t.Errorf("Ts... 应被识别为形参包展开基类")
+	}
+
+	widget := classes[2]
+	if !widget.IsCRTPBase(widget.BaseClasses[0]) {
+		t.Errorf("Widget 继承 %s 应被识别为CRTP", widget.BaseClasses[0])
+	}
+	node := classes[3]
+	if node.BaseClasses[0].FullName() != "Counter<Node<T>>" || !node.IsCRTPBase(node.BaseClasses[0]) {
+		t.Errorf("Node 的CRTP基类识别不正确: %v", node.BaseClasses)
+	}
+	if buffer.IsCRTPBase(buffer.BaseClasses[0]) {
+		t.Errorf("Storage<T, N> 不应被识别为CRTP")
+	}
+
+	explicit := classes[4]
+	if explicit.QualifiedName != "Buffer<bool>" || len(explicit.TemplateParams) != 0 || !sliceEqual(explicit.SpecializationArgs, []string{"bool"}) {
+		t.Errorf("显式特化识别不正确: %s %v %v", explicit.QualifiedName, explicit.TemplateParams, explicit.SpecializationArgs)
+	}
+	partial := classes[5]
+	if partial.QualifiedName != "Buffer<T*>" || len(partial.TemplateParams) != 1 {
+		t.Errorf("偏特化识别不正确: %s %v", partial.QualifiedName, partial.TemplateParams)
+	}
+	if classes[6].QualifiedName != "f()::Local" || classes[6].EnclosingFunction != "f" {
+		t.Errorf("函数模板中的局部类识别不正确: %s", classes[6].QualifiedName)
+	}
+}
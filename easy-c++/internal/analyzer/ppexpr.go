@@ -0,0 +1,287 @@
+package analyzer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// evaluate 计算 #if/#elif 的条件表达式
+// 先替换 defined 和 __has_include，再展开宏，剩余的标识符按标准视为 0
+func (pp *preprocessor) evaluate(toks []Token) (int64, error) {
+	var replaced []Token
+	for i := 0; i < len(toks); i++ {
+		t := toks[i]
+		switch t.Text {
+		case "defined":
+			name, next := definedOperand(toks, i+1)
+			if name == "" {
+				return 0, fmt.Errorf("defined 之后缺少宏名")
+			}
+			replaced = append(replaced, boolToken(pp.macros[name] != nil))
+			i = next - 1
+		case "__has_include", "__has_include_next", "__has_cpp_attribute", "__has_attribute", "__has_builtin":
+			// 无法判断的特性检测一律视为不支持
+			if i+1 < len(toks) && toks[i+1].Text == "(" {
+				i = matchingClose(toks, i+1) - 1
+			}
+			replaced = append(replaced, boolToken(false))
+		default:
+			replaced = append(replaced, t)
+		}
+	}
+
+	expanded := pp.expand(replaced)
+	for i, t := range expanded {
+		if t.Kind == TokenIdent {
+			expanded[i] = boolToken(t.Text == "true")
+		}
+	}
+
+	e := &exprParser{toks: expanded}
+	value, err := e.parseTernary()
+	if err != nil {
+		return 0, err
+	}
+	if e.pos < len(e.toks) {
+		return 0, fmt.Errorf("表达式中有多余的内容: %s", e.toks[e.pos].Text)
+	}
+	return value, nil
+}
+
+// definedOperand 读取 defined X 或 defined(X) 中的宏名，返回宏名和之后的位置
+func definedOperand(toks []Token, i int) (string, int) {
+	if i < len(toks) && toks[i].Kind == TokenIdent {
+		return toks[i].Text, i + 1
+	}
+	if i+2 < len(toks) && toks[i].Text == "(" && toks[i+1].Kind == TokenIdent && toks[i+2].Text == ")" {
+		return toks[i+1].Text, i + 3
+	}
+	return "", i
+}
+
+// boolToken 返回表示 1 或 0 的数字词法单元
+func boolToken(b bool) Token {
+	if b {
+		return Token{Kind: TokenNumber, Text: "1"}
+	}
+	return Token{Kind: TokenNumber, Text: "0"}
+}
+
+// exprParser 按运算符优先级计算预处理常量表达式
+type exprParser struct {
+	toks   []Token
+	pos    int
+	noEval bool // 正在解析不求值的操作数，如 0 && x 中的 x，只检查语法
+}
+
+// binaryPrecedence 二元运算符的优先级，数值越大结合越紧
+var binaryPrecedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"|":  3,
+	"^":  4,
+	"&":  5,
+	"==": 6, "!=": 6,
+	"<": 7, ">": 7, "<=": 7, ">=": 7,
+	"<<": 8, ">>": 8,
+	"+": 9, "-": 9,
+	"*": 10, "/": 10, "%": 10,
+}
+
+func (e *exprParser) peek() string {
+	if e.pos < len(e.toks) {
+		return e.toks[e.pos].Text
+	}
+	return ""
+}
+
+// parseTernary 解析条件表达式 a ? b : c
+func (e *exprParser) parseTernary() (int64, error) {
+	cond, err := e.parseBinary(1)
+	if err != nil || e.peek() != "?" {
+		return cond, err
+	}
+	e.pos++
+	then, err := e.operand(cond == 0, e.parseTernary)
+	if err != nil {
+		return 0, err
+	}
+	if e.peek() != ":" {
+		return 0, fmt.Errorf("条件表达式缺少 :")
+	}
+	e.pos++
+	otherwise, err := e.operand(cond != 0, e.parseTernary)
+	if err != nil {
+		return 0, err
+	}
+	if cond != 0 {
+		return then, nil
+	}
+	return otherwise, nil
+}
+
+// parseBinary 解析优先级不低于 minPrec 的二元表达式
+func (e *exprParser) parseBinary(minPrec int) (int64, error) {
+	left, err := e.parseUnary()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := e.peek()
+		prec, ok := binaryPrecedence[op]
+		if !ok || prec < minPrec {
+			return left, nil
+		}
+		e.pos++
+		// && 和 || 的左操作数已决定结果时不求值右操作数
+		shortCircuit := op == "&&" && left == 0 || op == "||" && left != 0
+		right, err := e.operand(shortCircuit, func() (int64, error) {
+			return e.parseBinary(prec + 1)
+		})
+		if err != nil {
+			return 0, err
+		}
+		if e.noEval {
+			continue
+		}
+		if left, err = applyBinary(op, left, right); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// operand 用 parse 解析一个操作数，unevaluated 为 true 时按标准不求值该操作数:
+// 仍然检查语法，但其中的除数为零等求值错误不报告，得到的值没有意义
+func (e *exprParser) operand(unevaluated bool, parse func() (int64, error)) (int64, error) {
+	if !unevaluated || e.noEval {
+		return parse()
+	}
+	e.noEval = true
+	defer func() { e.noEval = false }()
+	return parse()
+}
+
+// parseUnary 解析一元运算符、括号和字面量
+func (e *exprParser) parseUnary() (int64, error) {
+	if e.pos >= len(e.toks) {
+		return 0, fmt.Errorf("表达式不完整")
+	}
+	t := e.toks[e.pos]
+	e.pos++
+	switch t.Text {
+	case "!", "~", "-", "+":
+		v, err := e.parseUnary()
+		if err != nil {
+			return 0, err
+		}
+		switch t.Text {
+		case "!":
+			return boolValue(v == 0), nil
+		case "~":
+			return ^v, nil
+		case "-":
+			return -v, nil
+		}
+		return v, nil
+	case "(":
+		v, err := e.parseTernary()
+		if err != nil {
+			return 0, err
+		}
+		if e.peek() != ")" {
+			return 0, fmt.Errorf("缺少右括号")
+		}
+		e.pos++
+		return v, nil
+	}
+	switch t.Kind {
+	case TokenNumber:
+		return parseIntLiteral(t.Text)
+	case TokenChar:
+		return parseCharLiteral(t.Text)
+	}
+	return 0, fmt.Errorf("无法识别的记号: %s", t.Text)
+}
+
+// applyBinary 计算二元运算
+func applyBinary(op string, a, b int64) (int64, error) {
+	switch op {
+	case "||":
+		return boolValue(a != 0 || b != 0), nil
+	case "&&":
+		return boolValue(a != 0 && b != 0), nil
+	case "|":
+		return a | b, nil
+	case "^":
+		return a ^ b, nil
+	case "&":
+		return a & b, nil
+	case "==":
+		return boolValue(a == b), nil
+	case "!=":
+		return boolValue(a != b), nil
+	case "<":
+		return boolValue(a < b), nil
+	case ">":
+		return boolValue(a > b), nil
+	case "<=":
+		return boolValue(a <= b), nil
+	case ">=":
+		return boolValue(a >= b), nil
+	case "<<":
+		return a << uint64(b&63), nil
+	case ">>":
+		return a >> uint64(b&63), nil
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/", "%":
+		if b == 0 {
+			return 0, fmt.Errorf("除数为零")
+		}
+		if op == "/" {
+			return a / b, nil
+		}
+		return a % b, nil
+	}
+	return 0, fmt.Errorf("不支持的运算符: %s", op)
+}
+
+func boolValue(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// parseIntLiteral 解析整数字面量，支持十六进制、八进制、二进制、数字分隔符和 u/l/z 后缀
+func parseIntLiteral(text string) (int64, error) {
+	text = strings.ReplaceAll(text, "'", "")
+	text = strings.TrimRight(text, "uUlLzZ")
+	v, err := strconv.ParseInt(text, 0, 64)
+	if err != nil {
+		u, uerr := strconv.ParseUint(text, 0, 64)
+		if uerr != nil {
+			return 0, fmt.Errorf("无效的整数字面量 %s: %v", text, err)
+		}
+		return int64(u), nil
+	}
+	return v, nil
+}
+
+// parseCharLiteral 解析字符字面量，如 'A'、'\n'
+func parseCharLiteral(text string) (int64, error) {
+	if i := strings.IndexByte(text, '\''); i > 0 {
+		// 去掉 u8、L 等前缀
+		text = text[i:]
+	}
+	s, _, _, err := strconv.UnquoteChar(strings.Trim(text, "'"), '\'')
+	if err != nil {
+		return 0, fmt.Errorf("无效的字符字面量 %s: %v", text, err)
+	}
+	return int64(s), nil
+}
@@ -0,0 +1,400 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Macro 表示一个预处理宏
+type Macro struct {
+	Name         string   // 宏名
+	FunctionLike bool     // 是否为函数式宏，如 #define F(x) ...
+	Params       []string // 函数式宏的形参，不含可变参数 ...
+	Variadic     bool     // 函数式宏是否接受可变参数 __VA_ARGS__
+	Body         []Token  // 替换列表
+}
+
+// annotationMacros 常见框架中只起标注作用的宏，预先定义为空，源文件中的 #define 会覆盖它们
+var annotationMacros = []string{
+	"Q_OBJECT", "Q_GADGET", "Q_INTERFACES(...)", "Q_PROPERTY(...)", "Q_ENUM(...)", "Q_FLAG(...)",
+	"Q_DISABLE_COPY(...)", "Q_DECL_EXPORT", "Q_DECL_IMPORT", "Q_DECL_OVERRIDE", "Q_DECL_FINAL",
+	"UCLASS(...)", "USTRUCT(...)", "UINTERFACE(...)", "UENUM(...)", "UPROPERTY(...)", "UFUNCTION(...)",
+	"GENERATED_BODY()", "GENERATED_UCLASS_BODY()", "GENERATED_USTRUCT_BODY()", "GENERATED_IINTERFACE_BODY()",
+}
+
+// predefinedMacros 返回分析器预先定义的宏
+func predefinedMacros() map[string]*Macro {
+	macros := make(map[string]*Macro)
+	macros["__cplusplus"] = parseMacroDefinition("__cplusplus 201703L")
+	for _, def := range annotationMacros {
+		macro := parseMacroDefinition(def)
+		macros[macro.Name] = macro
+	}
+	return macros
+}
+
+// Define 定义一个预处理宏，等价于命令行的 -D name=value
+// name 可以带形参定义函数式宏，如 DECLARE(x)；value 为空时宏展开为空，可用于忽略导出宏
+func (a *CppAnalyzer) Define(name, value string) {
+	if macro := parseMacroDefinition(name + " " + value); macro != nil {
+		a.macros[macro.Name] = macro
+		a.userMacros[macro.Name] = true
+	}
+}
+
+// Undefine 取消一个预处理宏的定义，等价于命令行的 -U name
+func (a *CppAnalyzer) Undefine(name string) {
+	delete(a.macros, name)
+	a.userMacros[name] = true
+}
+
+// scannedFile 预先扫描一个文件的结果
+type scannedFile struct {
+	includes []string          // 活动分支中 #include 的文件，按包含顺序排列，不含找不到和被排除的文件
+	macros   map[string]*Macro // 文件中定义的宏，被 #undef 的宏值为 nil
+}
+
+// scanResult 预处理一个文件得到的 #include 指令和宏定义，只取决于文件的内容和初始宏表
+type scanResult struct {
+	includes []Include         // 活动分支中的 #include 指令，尚未解析路径
+	macros   map[string]*Macro // 与初始宏表相比新定义或重新定义的宏，被 #undef 的宏值为 nil
+}
+
+// scanFiles 预先扫描多个文件，收集各文件活动分支中 #define 的宏，并沿 #include 找到被包含的文件
+// 解析某个文件时，它直接或间接包含的文件中定义的宏也会生效，这样头文件中定义的导出宏等可以在源文件中展开
+// 返回 paths 以及通过包含关系找到的其他文件
+// 按包含关系逐层扫描: 同一层的文件并发预处理，再按文件顺序合并，结果与逐个扫描相同
+func (a *CppAnalyzer) scanFiles(ctx context.Context, paths []string) ([]string, error) {
+	a.scanned = make(map[string]*scannedFile)
+	a.registerFiles(paths)
+	queued := make(map[string]bool)
+	for _, path := range paths {
+		queued[path] = true
+	}
+	for start := 0; start < len(paths); {
+		level := paths[start:]
+		scans := make([]*scanResult, len(level))
+		err := a.parallel(ctx, len(level), func(i int) {
+			scans[i] = a.scanFile(level[i])
+		})
+		if err != nil {
+			return nil, err
+		}
+		start += len(level)
+
+		for i, scan := range scans {
+			if scan == nil {
+				continue
+			}
+			path := level[i]
+			// 用户定义的宏优先于文件中的定义
+			file := &scannedFile{macros: make(map[string]*Macro, len(scan.macros))}
+			for name, macro := range scan.macros {
+				if !a.userMacros[name] {
+					file.macros[name] = macro
+				}
+			}
+			for _, inc := range scan.includes {
+				resolved := a.resolveInclude(path, inc)
+				if resolved == "" || a.filter.skips(resolved) {
+					continue
+				}
+				file.includes = append(file.includes, resolved)
+				if !queued[resolved] {
+					queued[resolved] = true
+					paths = append(paths, resolved)
+				}
+			}
+			a.scanned[path] = file
+		}
+	}
+	return paths, nil
+}
+
+// scanFile 以 a.macros 为初始宏表预处理文件，返回其中的 #include 指令和定义的宏
+// 设置了缓存目录时优先使用缓存的结果；无法读取或预处理中发生 panic 时返回 nil，错误在正式解析时报告
+func (a *CppAnalyzer) scanFile(filePath string) (scan *scanResult) {
+	content, err := a.readSource(filePath)
+	if err != nil {
+		return nil
+	}
+	var key string
+	if a.cacheDir != "" {
+		key = cacheKey("scan", content, a.macros)
+		if scan := a.loadScanCache(key); scan != nil {
+			return scan
+		}
+	}
+	defer func() {
+		if recover() != nil {
+			scan = nil
+		}
+	}()
+	text, _, _ := decodeSource(content)
+	pp := newPreprocessor(a.macros)
+	pp.run(Tokenize(text))
+
+	scan = &scanResult{includes: pp.includes, macros: make(map[string]*Macro)}
+	for name, macro := range pp.macros {
+		if a.macros[name] != macro {
+			scan.macros[name] = macro
+		}
+	}
+	for name := range a.macros {
+		if _, defined := pp.macros[name]; !defined {
+			scan.macros[name] = nil
+		}
+	}
+	if key != "" {
+		a.storeScanCache(key, scan)
+	}
+	return scan
+}
+
+// macrosFor 返回解析 filePath 时的初始宏表: 预定义宏、用户定义的宏以及它直接或间接包含的文件中定义的宏
+// 被包含的文件按包含顺序应用，后包含的文件中的定义优先；没有被包含的文件中的宏不生效。
+// 文件自身定义的宏不预先生效，避免头文件的包含保护宏使整个文件被跳过
+func (a *CppAnalyzer) macrosFor(filePath string) map[string]*Macro {
+	file := a.scanned[filePath]
+	if file == nil || len(file.includes) == 0 {
+		return a.macros
+	}
+	macros := make(map[string]*Macro, len(a.macros))
+	for name, macro := range a.macros {
+		macros[name] = macro
+	}
+	visited := map[string]bool{filePath: true}
+	var apply func(path string)
+	apply = func(path string) {
+		included := a.scanned[path]
+		if visited[path] || included == nil {
+			return
+		}
+		visited[path] = true
+		// 被包含的文件自己包含的文件先生效
+		for _, inc := range included.includes {
+			apply(inc)
+		}
+		for name, macro := range included.macros {
+			if macro == nil {
+				delete(macros, name)
+			} else {
+				macros[name] = macro
+			}
+		}
+	}
+	for _, inc := range file.includes {
+		apply(inc)
+	}
+	return macros
+}
+
+// parseMacroDefinition 解析 #define 之后的内容，如 "F(x, ...) body"，无法识别宏名或形参表缺少右括号时返回 nil
+// 宏名之后紧跟左括号(中间没有空白)时为函数式宏
+func parseMacroDefinition(def string) *Macro {
+	toks := Tokenize(def)
+	if len(toks) == 0 || toks[0].Kind != TokenIdent {
+		return nil
+	}
+	macro := &Macro{Name: toks[0].Text}
+	body := toks[1:]
+	if len(body) > 0 && body[0].Text == "(" && body[0].Line == toks[0].Line &&
+		body[0].Column == toks[0].Column+len(toks[0].Text) {
+		macro.FunctionLike = true
+		end := matchingClose(body, 0)
+		if matchingOpen(body, end-1) != 0 {
+			return nil
+		}
+		for _, param := range splitTopLevel(body[1:end-1], ",") {
+			switch {
+			case len(param) == 1 && param[0].Text == "...":
+				macro.Variadic = true
+			case len(param) > 0:
+				macro.Params = append(macro.Params, param[0].Text)
+			}
+		}
+		body = body[end:]
+	}
+	macro.Body = body
+	return macro
+}
+
+// condFrame 条件编译栈中的一层 #if ... #endif
+type condFrame struct {
+	parentActive bool  // 外层是否处于活动分支
+	taken        bool  // 是否已有分支被选中
+	active       bool  // 当前分支是否活动
+	start        Token // 开始条件编译块的 #if 指令
+}
+
+// preprocessor 在词法单元序列上执行条件编译和宏展开
+type preprocessor struct {
+	macros      map[string]*Macro
+	conds       []condFrame
+	expansions  int          // 已展开的宏次数，超过 maxExpansions 后不再展开
+	includes    []Include    // 活动分支中的 #include 指令，尚未解析路径
+	diagnostics []Diagnostic // 预处理中发现的问题，尚未填写文件
+}
+
+// newPreprocessor 以给定的宏表为初始状态创建预处理器，文件中的 #define/#undef 不影响 macros 本身
+func newPreprocessor(macros map[string]*Macro) *preprocessor {
+	pp := &preprocessor{macros: make(map[string]*Macro, len(macros))}
+	for name, macro := range macros {
+		pp.macros[name] = macro
+	}
+	return pp
+}
+
+// active 判断当前位置是否处于活动分支
+func (pp *preprocessor) active() bool {
+	return len(pp.conds) == 0 || pp.conds[len(pp.conds)-1].active
+}
+
+// run 处理条件编译指令并展开宏，返回活动分支中的词法单元
+// 活动分支中的其他预处理指令(如 #include)原样保留；宏调用的实参不能跨越预处理指令
+func (pp *preprocessor) run(tokens []Token) []Token {
+	var out, pending []Token
+	for _, t := range tokens {
+		if t.Kind != TokenDirective {
+			if pp.active() {
+				pending = append(pending, t)
+			}
+			continue
+		}
+		out = append(out, pp.expand(pending)...)
+		pending = pending[:0]
+		name, rest := splitDirective(t.Text)
+		if pp.directive(name, rest, t) && pp.active() {
+			out = append(out, t)
+		}
+	}
+	for _, frame := range pp.conds {
+		pp.warn(CodeUnbalancedConditional, frame.start, "#%s 缺少对应的 #endif", directiveName(frame.start))
+	}
+	return append(out, pp.expand(pending)...)
+}
+
+// warn 记录一条预处理警告，t 为所在的指令
+func (pp *preprocessor) warn(code string, t Token, format string, args ...any) {
+	pp.diagnostics = append(pp.diagnostics, Diagnostic{
+		Severity: SeverityWarning,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+		Line:     t.Line,
+		Column:   t.Column,
+	})
+}
+
+// directiveName 返回预处理指令的名字，如 ifdef
+func directiveName(t Token) string {
+	name, _ := splitDirective(t.Text)
+	return name
+}
+
+// directive 执行一条预处理指令 t，返回该指令是否应保留在输出中
+func (pp *preprocessor) directive(name, rest string, t Token) bool {
+	switch name {
+	case "include", "include_next", "import":
+		if !pp.active() {
+			return false
+		}
+		if inc, ok := pp.includeDirective(rest, t.Line); ok {
+			pp.includes = append(pp.includes, inc)
+		}
+		return true
+	case "if", "ifdef", "ifndef":
+		frame := condFrame{parentActive: pp.active(), start: t}
+		if frame.parentActive {
+			frame.active = pp.condition(name, rest, t)
+			frame.taken = frame.active
+		}
+		pp.conds = append(pp.conds, frame)
+	case "elif", "elifdef", "elifndef":
+		if len(pp.conds) == 0 {
+			pp.warn(CodeUnbalancedConditional, t, "#%s 没有对应的 #if", name)
+			return false
+		}
+		frame := &pp.conds[len(pp.conds)-1]
+		frame.active = false
+		if frame.parentActive && !frame.taken {
+			frame.active = pp.condition(strings.TrimPrefix(name, "el"), rest, t)
+			frame.taken = frame.active
+		}
+	case "else":
+		if len(pp.conds) == 0 {
+			pp.warn(CodeUnbalancedConditional, t, "#else 没有对应的 #if")
+			return false
+		}
+		frame := &pp.conds[len(pp.conds)-1]
+		frame.active = frame.parentActive && !frame.taken
+		frame.taken = true
+	case "endif":
+		if len(pp.conds) == 0 {
+			pp.warn(CodeUnbalancedConditional, t, "#endif 没有对应的 #if")
+			return false
+		}
+		pp.conds = pp.conds[:len(pp.conds)-1]
+	case "define":
+		if pp.active() && !pp.define(rest) {
+			pp.warn(CodeInvalidDirective, t, "无法解析的宏定义: #define %s", rest)
+		}
+	case "undef":
+		if pp.active() {
+			delete(pp.macros, strings.TrimSpace(rest))
+		}
+	case "error", "warning":
+		if pp.active() {
+			pp.warn(CodeErrorDirective, t, "#%s %s", name, strings.TrimSpace(rest))
+		}
+	default:
+		return true
+	}
+	return false
+}
+
+// condition 计算指令 t 中 #if/#ifdef/#ifndef 的条件，无法计算的表达式报告警告后视为不成立
+func (pp *preprocessor) condition(kind, rest string, t Token) bool {
+	switch kind {
+	case "ifdef":
+		return pp.macros[firstWord(rest)] != nil
+	case "ifndef":
+		return pp.macros[firstWord(rest)] == nil
+	}
+	value, err := pp.evaluate(Tokenize(rest))
+	if err != nil {
+		pp.warn(CodeInvalidDirective, t, "无法计算 #%s 的条件 %s: %v，按不成立处理", directiveName(t), rest, err)
+		return false
+	}
+	return value != 0
+}
+
+// define 处理 #define 指令，无法解析宏定义时返回 false
+func (pp *preprocessor) define(rest string) bool {
+	macro := parseMacroDefinition(rest)
+	if macro == nil {
+		return false
+	}
+	pp.macros[macro.Name] = macro
+	return true
+}
+
+// splitDirective 将预处理指令拆分为指令名和其余部分，如 "# if X" 返回 "if" 和 "X"
+func splitDirective(text string) (string, string) {
+	text = strings.TrimSpace(strings.TrimPrefix(text, "#"))
+	end := 0
+	for end < len(text) && isIdentChar(text[end]) {
+		end++
+	}
+	return text[:end], strings.TrimSpace(text[end:])
+}
+
+// firstWord 返回字符串中的第一个标识符
+func firstWord(s string) string {
+	s = strings.TrimSpace(s)
+	end := 0
+	for end < len(s) && isIdentChar(s[end]) {
+		end++
+	}
+	return s[:end]
+}
@@ -0,0 +1,358 @@
+package analyzer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestPreprocessor_Conditionals 测试条件编译分支的选择
+func TestPreprocessor_Conditionals(t *testing.T) {
+	src := `
+#ifndef WIDGET_H
+#define WIDGET_H
+#define VERSION 3
+
+class Base {};
+class LegacyBase {};
+
+#if defined(USE_NEW_API) && VERSION >= 3
+class Widget : public Base {};
+#elif VERSION > 1
+class Widget : public LegacyBase {};
+#else
+class Widget {};
+#endif
+
+#ifdef _WIN32
+class WinOnly {};
+#endif
+
+#if 0
+class Disabled {
+#else
+class Enabled {
+#endif
+public:
+    int value;
+#if __cplusplus >= 201103L && !defined(NO_EXTRA)
+    int extra;
+#endif
+};
+
+#undef VERSION
+#if VERSION
+class Undefined {};
+#endif
+
+#if (1 << 4) == 0x10 ? 'A' == 65 : 0
+class Arithmetic {};
+#endif
+#endif
+`
+	tests := []struct {
+		name    string
+		defines map[string]string
+		undefs  []string
+		base    string
+		present []string
+		absent  []string
+		enabled []string
+	}{
+		{
+			name:    "默认",
+			base:    "LegacyBase",
+			present: []string{"Base", "LegacyBase", "Widget", "Enabled", "Arithmetic"},
+			absent:  []string{"WinOnly", "Disabled", "Undefined"},
+			enabled: []string{"int value", "int extra"},
+		},
+		{
+			name:    "-D USE_NEW_API -D _WIN32 -D NO_EXTRA",
+			defines: map[string]string{"USE_NEW_API": "", "_WIN32": "", "NO_EXTRA": ""},
+			base:    "Base",
+			present: []string{"WinOnly"},
+			enabled: []string{"int value"},
+		},
+		{
+			name:    "-U __cplusplus",
+			undefs:  []string{"__cplusplus"},
+			base:    "LegacyBase",
+			enabled: []string{"int value"},
+		},
+	}
+
+	for _, test := range tests {
+		a := NewCppAnalyzer()
+		for name, value := range test.defines {
+			a.Define(name, value)
+		}
+		for _, name := range test.undefs {
+			a.Undefine(name)
+		}
+		classes := a.analyzeSource(src)
+
+		for _, name := range test.present {
+			if findClassByName(classes, name) == nil {
+				t.Errorf("[%s] 应找到类 %s", test.name, name)
+			}
+		}
+		for _, name := range test.absent {
+			if findClassByName(classes, name) != nil {
+				t.Errorf("[%s] 不应找到非活动分支中的类 %s", test.name, name)
+			}
+		}
+
+		var widgets []*CppClass
+		for _, class := range classes {
+			if class.Name == "Widget" {
+				widgets = append(widgets, class)
+			}
+		}
+		if len(widgets) != 1 {
+			t.Errorf("[%s] 应只有一个 Widget 定义，实际: %d", test.name, len(widgets))
+		} else if !sliceEqual(baseNames(widgets[0]), []string{test.base}) {
+			t.Errorf("[%s] Widget 的基类不正确，期望: %s，实际: %v", test.name, test.base, baseNames(widgets[0]))
+		}
+
+		if enabled := findClassByName(classes, "Enabled"); enabled == nil {
+			t.Errorf("[%s] 未找到类 Enabled", test.name)
+		} else if !sliceEqual(memberDecls(enabled), test.enabled) {
+			t.Errorf("[%s] Enabled 的成员不正确，期望: %v，实际: %v", test.name, test.enabled, memberDecls(enabled))
+		}
+	}
+}
+
+// TestPreprocessor_Evaluate 测试 #if 表达式求值
+func TestPreprocessor_Evaluate(t *testing.T) {
+	pp := newPreprocessor(predefinedMacros())
+	pp.define("TWO 2")
+	pp.define("ALIAS TWO")
+	tests := []struct {
+		expr     string
+		expected int64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10 - 4 - 3", 3},
+		{"ALIAS * TWO == 4", 1},
+		{"defined ALIAS && !defined(MISSING)", 1},
+		{"MISSING + 1", 1},
+		{"__has_include(<optional>) || false", 0},
+		{"-1 < 0 ? 0x10 : 010", 16},
+		{"1'000 % 7", 6},
+		{"~0 & 0xFFu", 255},
+		{"__cplusplus >= 201703L", 1},
+		// 不求值的操作数中的除数为零不是错误
+		{"0 && 1/0", 0},
+		{"1 || 1/0", 1},
+		{"1 ? 2 : 1/0", 2},
+		{"0 ? 1 % 0 : 3", 3},
+		{"0 && (1/0 || 2/0)", 0},
+	}
+	for _, test := range tests {
+		value, err := pp.evaluate(Tokenize(test.expr))
+		if err != nil {
+			t.Errorf("计算 '%s' 出错: %v", test.expr, err)
+			continue
+		}
+		if value != test.expected {
+			t.Errorf("计算 '%s' 失败，期望: %d，实际: %d", test.expr, test.expected, value)
+		}
+	}
+
+	for _, expr := range []string{"1 +", "(1", "1 / 0", "defined", "1 && 1/0", "0 || 1/0", "0 ? 1 : 1/0", "0 && (1"} {
+		if _, err := pp.evaluate(Tokenize(expr)); err == nil {
+			t.Errorf("计算 '%s' 应返回错误", expr)
+		}
+	}
+}
+
+// TestPreprocessor_Macros 测试对象式宏和函数式宏的展开
+func TestPreprocessor_Macros(t *testing.T) {
+	src := `
+#define MYLIB_API __declspec(dllexport)
+#define DECLARE_DYNAMIC_CLASS(name) class name : public wxObject { public: static wxClassInfo ms_classInfo; };
+#define PASTE(a, b) a##b
+#define BASES(...) public A __VA_OPT__(,) __VA_ARGS__
+#define STR(x) #x
+#define SELF SELF
+#define ALIAS DECLARE_DYNAMIC_CLASS
+
+class Base {};
+class MYLIB_API Widget : public Base {};
+class OTHER_API Gadget final : public Base {};
+DECLARE_DYNAMIC_CLASS(Foo)
+ALIAS(Bar)
+
+UCLASS(Blueprintable)
+class AFoo : public AActor {
+    GENERATED_BODY()
+public:
+    UPROPERTY(EditAnywhere)
+    int health;
+};
+
+class PASTE(My, Thing) : BASES(public B) {
+    const char* name = STR(My  Thing);
+    SELF self;
+    EXPORTED_DECL(x) int y;
+};
+class PASTE(, Empty) : BASES() {};
+`
+	a := NewCppAnalyzer()
+	a.Define("EXPORTED_DECL(x)", "")
+	classes := a.analyzeSource(src)
+
+	tests := []struct {
+		name    string
+		bases   []string
+		members []string
+	}{
+		{"Widget", []string{"Base"}, nil},
+		{"Gadget", []string{"Base"}, nil},
+		{"Foo", []string{"wxObject"}, []string{"static wxClassInfo ms_classInfo"}},
+		{"Bar", []string{"wxObject"}, []string{"static wxClassInfo ms_classInfo"}},
+		{"AFoo", []string{"AActor"}, []string{"int health"}},
+		{"MyThing", []string{"A", "B"}, []string{`const char* name = "My Thing"`, "SELF self", "int y"}},
+		{"Empty", []string{"A"}, nil},
+	}
+	for _, test := range tests {
+		class := findClassByName(classes, test.name)
+		if class == nil {
+			t.Errorf("未找到类 %s", test.name)
+			continue
+		}
+		if !sliceEqual(baseNames(class), test.bases) {
+			t.Errorf("类 %s 的基类不正确，期望: %v，实际: %v", test.name, test.bases, baseNames(class))
+		}
+		if !sliceEqual(memberDecls(class), test.members) {
+			t.Errorf("类 %s 的成员不正确，期望: %v，实际: %v", test.name, test.members, memberDecls(class))
+		}
+	}
+	if len(classes) != len(tests)+1 {
+		var names []string
+		for _, class := range classes {
+			names = append(names, class.Name)
+		}
+		t.Errorf("期望找到%d个类，实际: %v", len(tests)+1, names)
+	}
+	if foo := findClassByName(classes, "Foo"); foo != nil && foo.LineNumber != 13 {
+		t.Errorf("宏展开出的类应取宏调用处的行号13，实际: %d", foo.LineNumber)
+	}
+}
+
+// TestAnalyzeFiles_ScannedMacros 测试直接或间接包含的文件中定义的宏在多文件分析时生效
+func TestAnalyzeFiles_ScannedMacros(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"export.h": `#ifndef EXPORT_H
+#define EXPORT_H
+#define core_api [[gnu::visibility("default")]]
+#define make_node(name) class name : public Node {};
+#endif
+`,
+		"config.h": `#include "export.h"
+`,
+		"nodes.cpp": `#include "config.h"
+class core_api Node {};
+make_node(Leaf)
+`,
+	}
+	var paths []string
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+		paths = append(paths, path)
+	}
+
+	classes, err := NewCppAnalyzer().AnalyzeFiles(paths)
+	if err != nil {
+		t.Fatalf("多文件分析失败: %v", err)
+	}
+	if node := findClassByName(classes, "Node"); node == nil {
+		t.Errorf("未找到类 Node")
+	}
+	if leaf := findClassByName(classes, "Leaf"); leaf == nil {
+		t.Errorf("未找到宏展开出的类 Leaf")
+	} else if !sliceEqual(baseNames(leaf), []string{"Node"}) {
+		t.Errorf("Leaf 应继承自 Node，实际: %v", baseNames(leaf))
+	}
+}
+
+// TestAnalyzeFiles_ScannedMacrosNotLeaked 测试没有被包含的文件中定义的宏不影响其他文件
+func TestAnalyzeFiles_ScannedMacrosNotLeaked(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"hack.h":    "#define private public\nclass Hack {};\n",
+		"base.h":    "class Base {};\n",
+		"widget.h":  "#include \"base.h\"\nclass Widget : private Base {};\n",
+		"tests.cpp": "#include \"hack.h\"\n#include \"base.h\"\nclass Probe : private Base {};\n",
+	}
+	var paths []string
+	for _, name := range []string{"hack.h", "base.h", "widget.h", "tests.cpp"} {
+		path := filepath.Join(tempDir, name)
+		if err := os.WriteFile(path, []byte(files[name]), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+		paths = append(paths, path)
+	}
+
+	classes, err := NewCppAnalyzer().AnalyzeFiles(paths)
+	if err != nil {
+		t.Fatalf("多文件分析失败: %v", err)
+	}
+	tests := []struct {
+		class  string
+		access string
+	}{
+		{"Widget", "private"},
+		{"Probe", "public"},
+	}
+	for _, tt := range tests {
+		class := findClassByName(classes, tt.class)
+		if class == nil {
+			t.Errorf("未找到类 %s", tt.class)
+			continue
+		}
+		if len(class.BaseClasses) != 1 || class.BaseClasses[0].Access != tt.access {
+			t.Errorf("%s 的基类继承方式应为 %s，实际: %+v", tt.class, tt.access, class.BaseClasses)
+		}
+	}
+}
+
+// TestAnalyzeProject_MalformedDefine 测试形参表缺少右括号的宏定义报告诊断，不影响其他文件的分析
+func TestAnalyzeProject_MalformedDefine(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"broken.h": "class Broken {};\n#define F(\n",
+		"open.h":   "#define G(a, (b)\n",
+		"shape.h":  "#include \"broken.h\"\nclass Shape : public Broken {};\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+	}
+
+	a := NewCppAnalyzer()
+	a.SetJobs(4)
+	classes, err := a.AnalyzeProject(tempDir)
+	if err != nil {
+		t.Fatalf("项目分析失败: %v", err)
+	}
+	if shape := findClassByName(classes, "Shape"); shape == nil || !sliceEqual(baseNames(shape), []string{"Broken"}) {
+		t.Errorf("未正确分析类 Shape: %v", shape)
+	}
+	var locations []string
+	for _, d := range a.Diagnostics() {
+		if d.Code == CodeInvalidDirective {
+			locations = append(locations, fmt.Sprintf("%s:%d", filepath.Base(d.File), d.Line))
+		}
+	}
+	if !sliceEqual(locations, []string{"broken.h:2", "open.h:1"}) {
+		t.Errorf("无法解析的宏定义的诊断不正确: %v", locations)
+	}
+}
@@ -0,0 +1,87 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// 分析过程中所有的文件访问都经过下面几个函数:
+// a.fsys 为 nil 时访问操作系统的文件，否则访问 AnalyzeFS 传入的文件系统，此时路径为以 / 分隔的相对路径
+
+// fsName 把分析中使用的路径转换为 fs.FS 中的名字
+func fsName(filePath string) string {
+	return filepath.ToSlash(filepath.Clean(filePath))
+}
+
+// readSource 读取文件的全部内容
+func (a *CppAnalyzer) readSource(filePath string) ([]byte, error) {
+	if a.fsys == nil {
+		return os.ReadFile(filePath)
+	}
+	return fs.ReadFile(a.fsys, fsName(filePath))
+}
+
+// openSource 打开文件用于读取
+func (a *CppAnalyzer) openSource(filePath string) (io.ReadCloser, error) {
+	if a.fsys == nil {
+		return os.Open(filePath)
+	}
+	return a.fsys.Open(fsName(filePath))
+}
+
+// statSource 返回文件的信息，跟随符号链接
+func (a *CppAnalyzer) statSource(filePath string) (fs.FileInfo, error) {
+	if a.fsys == nil {
+		return os.Stat(filePath)
+	}
+	return fs.Stat(a.fsys, fsName(filePath))
+}
+
+// readSourceDir 返回目录中按文件名排序的条目
+func (a *CppAnalyzer) readSourceDir(dir string) ([]fs.DirEntry, error) {
+	if a.fsys == nil {
+		return os.ReadDir(dir)
+	}
+	return fs.ReadDir(a.fsys, fsName(dir))
+}
+
+// realPath 返回目录解析符号链接后的路径，用于发现符号链接循环；fs.FS 中没有符号链接，返回原路径
+func (a *CppAnalyzer) realPath(dir string) (string, error) {
+	if a.fsys == nil {
+		return filepath.EvalSymlinks(dir)
+	}
+	return fsName(dir), nil
+}
+
+// AnalyzeReader 分析从 r 读取的源代码，name 为虚拟的文件名，用于类的 FilePath、文件种类和诊断中的位置
+// 文件种类只按 name 的扩展名判断，不会在文件系统中查找 name。与 AnalyzeFile 相同，基类只在这段代码内解析
+func (a *CppAnalyzer) AnalyzeReader(name string, r io.Reader) ([]*CppClass, error) {
+	a.reset()
+	a.scanned = nil
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("无法读取 %s: %v", name, err)
+	}
+	classes := a.addFile(name, a.extensionKind(name), a.parseContent(name, content))
+	a.pruneCache()
+	return a.resolveInterFileInheritance(classes), nil
+}
+
+// AnalyzeFS 分析文件系统 fsys 中 root 目录下的整个项目，如 os.DirFS、zip.Reader 或 OpenArchive 的结果
+// 路径为 fs.FS 中以 / 分隔的名字，root 为 "." 时分析整个文件系统；-I 指定的搜索路径同样在 fsys 中查找。
+// 其余行为与 AnalyzeProject 相同
+func (a *CppAnalyzer) AnalyzeFS(fsys fs.FS, root string) ([]*CppClass, error) {
+	return a.AnalyzeFSContext(context.Background(), fsys, root)
+}
+
+// AnalyzeFSContext 与 AnalyzeFS 相同，ctx 被取消时停止分析并返回 ctx.Err()
+func (a *CppAnalyzer) AnalyzeFSContext(ctx context.Context, fsys fs.FS, root string) ([]*CppClass, error) {
+	a.reset()
+	a.fsys = fsys
+	defer func() { a.fsys = nil }()
+	return a.analyzeProject(ctx, fsName(root))
+}
@@ -0,0 +1,237 @@
+package analyzer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// sourceFiles 测试用的项目，include 目录需要作为搜索路径
+var sourceFiles = map[string]string{
+	"proj/include/shape.h": "#pragma once\nclass Shape { public: virtual void draw() = 0; };\n",
+	"proj/src/circle.h":    "#include <shape.h>\nclass Circle : public Shape { public: void draw() override; };\n",
+	"proj/src/circle.cpp":  "#include \"circle.h\"\nvoid Circle::draw() {}\n",
+	"proj/README.md":       "class NotCode\n",
+}
+
+// checkSourceClasses 检查分析 sourceFiles 得到的类
+func checkSourceClasses(t *testing.T, classes []*CppClass, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("分析失败: %v", err)
+	}
+	if len(classes) != 2 {
+		t.Fatalf("期望 2 个类，实际: %d", len(classes))
+	}
+	circle := findClassByName(classes, "Circle")
+	if circle == nil || circle.BaseClasses[0].External || circle.FilePath != "proj/src/circle.h" {
+		t.Errorf("Circle 应继承自通过搜索路径找到的 Shape: %+v", circle)
+	}
+	if circle != nil && circle.Methods[0].DefinitionFile != "proj/src/circle.cpp" {
+		t.Errorf("Circle::draw 的定义位置不正确: %q", circle.Methods[0].DefinitionFile)
+	}
+}
+
+// TestAnalyzeFS 测试分析 fs.FS 中的项目和源代码压缩包
+func TestAnalyzeFS(t *testing.T) {
+	mapFS := fstest.MapFS{}
+	for name, content := range sourceFiles {
+		mapFS[name] = &fstest.MapFile{Data: []byte(content)}
+	}
+	a := NewCppAnalyzer()
+	a.AddIncludePath("proj/include")
+	classes, err := a.AnalyzeFS(mapFS, ".")
+	checkSourceClasses(t, classes, err)
+
+	// 压缩包
+	tempDir := t.TempDir()
+	zipPath := filepath.Join(tempDir, "proj.zip")
+	tarPath := filepath.Join(tempDir, "proj.tar.gz")
+	writeArchive(t, zipPath, func(w io.Writer) func(name, content string) {
+		zw := zip.NewWriter(w)
+		return func(name, content string) {
+			if name == "" {
+				zw.Close()
+				return
+			}
+			f, _ := zw.Create(name)
+			io.WriteString(f, content)
+		}
+	})
+	writeArchive(t, tarPath, func(w io.Writer) func(name, content string) {
+		gw := gzip.NewWriter(w)
+		tw := tar.NewWriter(gw)
+		return func(name, content string) {
+			if name == "" {
+				tw.Close()
+				gw.Close()
+				return
+			}
+			tw.WriteHeader(&tar.Header{Name: "./" + name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+			io.WriteString(tw, content)
+		}
+	})
+	for _, path := range []string{zipPath, tarPath} {
+		if !IsArchive(path) {
+			t.Errorf("%s 应识别为压缩包", path)
+		}
+		fsys, err := OpenArchive(path)
+		if err != nil {
+			t.Fatalf("打开压缩包失败: %v", err)
+		}
+		if err := fstest.TestFS(fsys, "proj/include/shape.h", "proj/src/circle.cpp"); err != nil {
+			t.Errorf("%s 不是有效的 fs.FS: %v", filepath.Base(path), err)
+		}
+		classes, err := a.AnalyzeFS(fsys, ".")
+		checkSourceClasses(t, classes, err)
+		classes, err = a.AnalyzeArchive(path)
+		checkSourceClasses(t, classes, err)
+	}
+	if _, err := OpenArchive(filepath.Join(tempDir, "missing.zip")); err == nil {
+		t.Error("打开不存在的压缩包应返回错误")
+	}
+}
+
+// writeArchive 按 sourceFiles 写入压缩包，open 返回的函数以空文件名结束写入
+func writeArchive(t *testing.T, path string, open func(w io.Writer) func(name, content string)) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("创建压缩包失败: %v", err)
+	}
+	defer file.Close()
+	add := open(file)
+	for name, content := range sourceFiles {
+		add(name, content)
+	}
+	add("", "")
+}
+
+// tarEntry 测试中写入 tar 包的条目，size 大于内容长度时以零字节补足
+type tarEntry struct {
+	name    string
+	dir     bool
+	content string
+	size    int64
+}
+
+// streamTar 在后台把条目写入 tar 包，返回读取 tar 包的一端；读取方关闭后停止写入
+func streamTar(entries ...tarEntry) *io.PipeReader {
+	pr, pw := io.Pipe()
+	go func() {
+		tw := tar.NewWriter(pw)
+		for _, entry := range entries {
+			if entry.dir {
+				tw.WriteHeader(&tar.Header{Name: entry.name + "/", Mode: 0755, Typeflag: tar.TypeDir})
+				continue
+			}
+			size := max(entry.size, int64(len(entry.content)))
+			if tw.WriteHeader(&tar.Header{Name: entry.name, Mode: 0644, Size: size, Typeflag: tar.TypeReg}) != nil {
+				break
+			}
+			io.WriteString(tw, entry.content)
+			zeros := make([]byte, 1<<20)
+			for rest := size - int64(len(entry.content)); rest > 0; rest -= int64(len(zeros)) {
+				if _, err := tw.Write(zeros[:min(rest, int64(len(zeros)))]); err != nil {
+					break
+				}
+			}
+		}
+		pw.CloseWithError(tw.Close())
+	}()
+	return pr
+}
+
+// TestReadTar 测试读取 tar 包时的筛选、大小限制以及同名文件和目录的处理
+func TestReadTar(t *testing.T) {
+	a := NewCppAnalyzer()
+	big := tarEntry{name: "assets/big.bin", size: maxArchiveFileSize + 1}
+	shape := tarEntry{name: "src/shape.h", content: "class Shape {};\n"}
+
+	// 超过大小限制的文件
+	r := streamTar(big, shape)
+	_, err := readTar("big.tar", r, nil)
+	r.Close()
+	if err == nil || !strings.Contains(err.Error(), "assets/big.bin") {
+		t.Errorf("超过大小限制的文件应返回错误，实际: %v", err)
+	}
+
+	// 筛选掉的文件不读入，也不受大小限制
+	r = streamTar(big, shape, tarEntry{name: "src/Makefile", content: "all:\n"}, tarEntry{name: "README.md", content: "# proj\n"})
+	fsys, err := readTar("big.tar", r, a.keepArchiveFile)
+	r.Close()
+	if err != nil {
+		t.Fatalf("读取 tar 包失败: %v", err)
+	}
+	if err := fstest.TestFS(fsys, "src/shape.h", "src/Makefile"); err != nil {
+		t.Errorf("筛选后的 tar 包不是有效的 fs.FS: %v", err)
+	}
+	for _, name := range []string{"assets/big.bin", "README.md"} {
+		if _, err := fs.Stat(fsys, name); err == nil {
+			t.Errorf("%s 不是C++文件，不应读入", name)
+		}
+	}
+
+	// 同名的文件和目录以后出现的为准
+	r = streamTar(
+		tarEntry{name: "a/x.h", content: "class X {};\n"},
+		tarEntry{name: "a", content: "class A {};\n"},
+		tarEntry{name: "b", content: "class B {};\n"},
+		tarEntry{name: "b/y.h", content: "class Y {};\n"},
+		tarEntry{name: "c", content: "class C {};\n"},
+		tarEntry{name: "c", dir: true},
+	)
+	fsys, err = readTar("clash.tar", r, nil)
+	r.Close()
+	if err != nil {
+		t.Fatalf("读取 tar 包失败: %v", err)
+	}
+	if err := fstest.TestFS(fsys, "a", "b/y.h"); err != nil {
+		t.Errorf("同名文件和目录处理后不是有效的 fs.FS: %v", err)
+	}
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		t.Fatalf("读取根目录失败: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, fmt.Sprintf("%s:%v", entry.Name(), entry.IsDir()))
+	}
+	if !sliceEqual(names, []string{"a:false", "b:true", "c:true"}) {
+		t.Errorf("根目录的条目不正确: %v", names)
+	}
+}
+
+// TestAnalyzeReader 测试分析从 io.Reader 读取的源代码
+func TestAnalyzeReader(t *testing.T) {
+	a := NewCppAnalyzer()
+	src := "#ifdef LEGACY\nclass Old {};\n#endif\nclass Base {};\nclass Derived : public Base {};\n"
+	classes, err := a.AnalyzeReader("snippet.h", strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("分析失败: %v", err)
+	}
+	if len(classes) != 2 || classes[0].FilePath != "snippet.h" || classes[0].FileKind != FileHeader {
+		t.Fatalf("类或其文件信息不正确: %+v", classes)
+	}
+	if derived := findClassByName(classes, "Derived"); derived == nil || derived.BaseClasses[0].External || derived.LineNumber != 5 {
+		t.Errorf("Derived 解析不正确: %+v", derived)
+	}
+
+	// 虚拟的文件名不在文件系统中查找，即使存在同名的C++头文件
+	name := filepath.Join(t.TempDir(), "widget")
+	if err := os.WriteFile(name, []byte("#pragma once\nclass Widget {};\n"), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+	classes, err = a.AnalyzeReader(name, strings.NewReader("class Snippet {};\n"))
+	if err != nil || len(classes) != 1 || classes[0].FileKind != "" {
+		t.Errorf("没有扩展名的虚拟文件名不应判断文件种类: %+v %v", classes, err)
+	}
+}
@@ -0,0 +1,168 @@
+package analyzer
+
+import (
+	"strings"
+)
+
+// TemplateParam 表示一个模板形参
+type TemplateParam struct {
+	Kind     string // typename、class、template<...> class 或非类型形参的类型，如 int
+	Name     string // 形参名，未命名时为空
+	Default  string // 默认实参
+	Variadic bool   // 是否为形参包，如 typename... Ts
+}
+
+// String 返回模板形参的源代码形式
+func (tp TemplateParam) String() string {
+	s := tp.Kind
+	if tp.Variadic {
+		s += "..."
+	}
+	if tp.Name != "" {
+		s += " " + tp.Name
+	}
+	if tp.Default != "" {
+		s += " = " + tp.Default
+	}
+	return s
+}
+
+// matchingAngle 返回与toks[open]处 < 匹配的 > 之后的位置，>> 视为两个 >
+// 不匹配时返回序列末尾
+func matchingAngle(toks []Token, open int) int {
+	depth := 0
+	for i := open; i < len(toks); i++ {
+		switch toks[i].Text {
+		case "<":
+			depth++
+		case ">":
+			depth--
+		case ">>":
+			depth -= 2
+		case "(", "[", "{":
+			i = matchingClose(toks, i) - 1
+			continue
+		case ";", "}":
+			return i
+		}
+		if depth <= 0 {
+			return i + 1
+		}
+	}
+	return len(toks)
+}
+
+// angleContents 返回toks[open]处尖括号内的词法单元及匹配的 > 之后的位置
+// 以 >> 结束时，其中的第一个 > 属于尖括号内部
+func angleContents(toks []Token, open int) ([]Token, int) {
+	end := matchingAngle(toks, open)
+	if end-1 <= open || end > len(toks) {
+		return nil, end
+	}
+	inner := toks[open+1 : end-1]
+	switch closing := toks[end-1]; closing.Text {
+	case ">>":
+		inner = append(append([]Token{}, inner...), Token{Kind: TokenPunct, Text: ">", Line: closing.Line, Column: closing.Column})
+	case ">":
+	default:
+		// 未闭合的尖括号
+		inner = toks[open+1 : end]
+	}
+	return inner, end
+}
+
+// parseTemplateParams 解析 template<...> 尖括号内的模板形参列表
+func parseTemplateParams(toks []Token) []TemplateParam {
+	params := []TemplateParam{}
+	for _, part := range splitTopLevel(toks, ",") {
+		var param TemplateParam
+		if eq := findTopLevel(part, "="); eq >= 0 {
+			param.Default = joinTokens(part[eq+1:])
+			part = part[:eq]
+		}
+
+		var kind []Token
+		for _, t := range part {
+			if t.Text == "..." {
+				param.Variadic = true
+				continue
+			}
+			kind = append(kind, t)
+		}
+		// 最后一个标识符是形参名，除非它本身就是类型关键字(如 typename = void)
+		if n := len(kind); n > 1 && kind[n-1].Kind == TokenIdent && !isTemplateParamKeyword(kind[n-1].Text) {
+			param.Name = kind[n-1].Text
+			kind = kind[:n-1]
+		}
+		param.Kind = joinTokens(kind)
+		params = append(params, param)
+	}
+	return params
+}
+
+// isTemplateParamKeyword 判断标识符是否只能作为模板形参的种类出现
+func isTemplateParamKeyword(s string) bool {
+	return s == "typename" || s == "class" || isTypeKeyword(s)
+}
+
+// templateArgs 解析尖括号内的模板实参列表
+func templateArgs(toks []Token) []string {
+	args := []string{}
+	for _, part := range splitTopLevel(toks, ",") {
+		args = append(args, joinTokens(part))
+	}
+	return args
+}
+
+// TemplateName 去掉名字中的模板实参列表和形参包展开，如 ns::Base<T>... 返回 ns::Base
+func TemplateName(name string) string {
+	var sb strings.Builder
+	depth := 0
+	for _, r := range strings.TrimSuffix(name, "...") {
+		switch {
+		case r == '<':
+			depth++
+		case r == '>':
+			depth--
+		case depth == 0:
+			sb.WriteRune(r)
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// IsCRTPBase 判断基类是否以派生类自身作为模板实参(奇异递归模板模式)
+func (c *CppClass) IsCRTPBase(base BaseSpecifier) bool {
+	for _, arg := range base.TemplateArgs {
+		name := TemplateName(arg)
+		if name == c.Name || name == TemplateName(c.QualifiedName) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTemplateParam 判断名字是否为该类的模板形参
+func (c *CppClass) IsTemplateParam(name string) bool {
+	for _, param := range c.TemplateParams {
+		if param.Name != "" && param.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// TemplateHeader 返回类的模板头，如 template<typename T>；显式特化返回 template<>，非模板类返回空
+func (c *CppClass) TemplateHeader() string {
+	if len(c.TemplateParams) == 0 {
+		if len(c.SpecializationArgs) > 0 {
+			return "template<>"
+		}
+		return ""
+	}
+	var params []string
+	for _, param := range c.TemplateParams {
+		params = append(params, param.String())
+	}
+	return "template<" + strings.Join(params, ", ") + ">"
+}
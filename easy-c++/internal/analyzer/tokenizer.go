@@ -0,0 +1,304 @@
+package analyzer
+
+import (
+	"strings"
+)
+
+// TokenKind 词法单元类型
+type TokenKind int
+
+const (
+	TokenIdent     TokenKind = iota // 标识符和关键字
+	TokenNumber                     // 数字字面量
+	TokenString                     // 字符串字面量(含原始字符串)
+	TokenChar                       // 字符字面量
+	TokenPunct                      // 运算符和标点
+	TokenDirective                  // 预处理指令(整条逻辑行)
+	TokenEOF                        // 序列结束，只作为越界访问时返回的标记，不出现在词法单元序列中
+)
+
+// Token 表示一个C++词法单元
+type Token struct {
+	Kind   TokenKind
+	Text   string
+	Line   int // 起始行号(从1开始)
+	Column int // 起始列号(从1开始)
+}
+
+// punctuators 多字符运算符，按长度从长到短排列以保证最长匹配
+var punctuators = []string{
+	"<<=", ">>=", "->*", "...", "<=>",
+	"::", "->", ".*", "++", "--", "<<", ">>", "<=", ">=", "==", "!=",
+	"&&", "||", "+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=", "##",
+}
+
+// lexer 将源代码切分为词法单元
+type lexer struct {
+	src       string
+	pos       int
+	line      int
+	col       int
+	lineStart bool // 当前位置之前本行只有空白
+	tokens    []Token
+}
+
+// Tokenize 将C++源代码切分为词法单元序列
+// 注释被丢弃，预处理指令以整行的 TokenDirective 形式保留。
+// 开头的 BOM 被忽略，\r\n 和单独的 \r 都作为换行；列号按字符而不是字节计算
+func Tokenize(src string) []Token {
+	src = strings.TrimPrefix(src, "\ufeff")
+	if strings.Contains(src, "\r") {
+		src = strings.ReplaceAll(strings.ReplaceAll(src, "\r\n", "\n"), "\r", "\n")
+	}
+	l := &lexer{src: src, line: 1, col: 1, lineStart: true}
+	l.run()
+	return l.tokens
+}
+
+// advance 前进n个字节并维护行列号
+func (l *lexer) advance(n int) {
+	for i := 0; i < n && l.pos < len(l.src); i++ {
+		switch c := l.src[l.pos]; {
+		case c == '\n':
+			l.line++
+			l.col = 1
+			l.lineStart = true
+		case c&0xC0 == 0x80:
+			// UTF-8 多字节字符的后续字节不再占列
+		default:
+			l.col++
+		}
+		l.pos++
+	}
+}
+
+// peek 返回相对当前位置偏移off处的字节
+func (l *lexer) peek(off int) byte {
+	if l.pos+off < len(l.src) {
+		return l.src[l.pos+off]
+	}
+	return 0
+}
+
+// emit 追加一个词法单元
+func (l *lexer) emit(kind TokenKind, start, line, col int) {
+	l.tokens = append(l.tokens, Token{Kind: kind, Text: l.src[start:l.pos], Line: line, Column: col})
+	l.lineStart = false
+}
+
+func (l *lexer) run() {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '\n':
+			l.advance(1)
+		case c == ' ' || c == '\t' || c == '\f' || c == '\v':
+			l.advance(1)
+		case c == '\\' && l.peek(1) == '\n':
+			// 续行符
+			l.advance(2)
+		case c == '/' && l.peek(1) == '/':
+			l.skipLineComment()
+		case c == '/' && l.peek(1) == '*':
+			l.skipBlockComment()
+		case c == '#' && l.lineStart:
+			l.lexDirective()
+		case isIdentStart(c):
+			l.lexIdentOrLiteral()
+		case isDigit(c) || (c == '.' && isDigit(l.peek(1))):
+			l.lexNumber()
+		case c == '"':
+			l.lexQuoted(l.pos, l.line, l.col, '"', TokenString)
+		case c == '\'':
+			l.lexQuoted(l.pos, l.line, l.col, '\'', TokenChar)
+		default:
+			l.lexPunct()
+		}
+	}
+}
+
+// skipLineComment 跳过单行注释(支持续行)
+func (l *lexer) skipLineComment() {
+	for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+		if l.src[l.pos] == '\\' && l.peek(1) == '\n' {
+			l.advance(2)
+			continue
+		}
+		l.advance(1)
+	}
+}
+
+// skipBlockComment 跳过块注释，未闭合的块注释延伸到文件末尾
+func (l *lexer) skipBlockComment() {
+	l.advance(2)
+	for l.pos < len(l.src) {
+		if l.src[l.pos] == '*' && l.peek(1) == '/' {
+			l.advance(2)
+			return
+		}
+		l.advance(1)
+	}
+}
+
+// lexDirective 读取一条完整的预处理指令，去掉注释并拼接续行
+func (l *lexer) lexDirective() {
+	line, col := l.line, l.col
+	var sb strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c == '\n' {
+			break
+		}
+		switch {
+		case c == '\\' && l.peek(1) == '\n':
+			l.advance(2)
+			sb.WriteByte(' ')
+		case c == '/' && l.peek(1) == '/':
+			l.skipLineComment()
+		case c == '/' && l.peek(1) == '*':
+			l.skipBlockComment()
+			sb.WriteByte(' ')
+		case c == '"' || c == '\'':
+			start := l.pos
+			l.skipQuoted(c)
+			sb.WriteString(l.src[start:l.pos])
+		default:
+			sb.WriteByte(c)
+			l.advance(1)
+		}
+	}
+	l.tokens = append(l.tokens, Token{Kind: TokenDirective, Text: strings.TrimSpace(sb.String()), Line: line, Column: col})
+	l.lineStart = true
+}
+
+// lexIdentOrLiteral 读取标识符，或带前缀的字符串/字符字面量(u8"x", L'x', R"(x)")
+func (l *lexer) lexIdentOrLiteral() {
+	start, line, col := l.pos, l.line, l.col
+	end := l.pos
+	for end < len(l.src) && isIdentChar(l.src[end]) {
+		end++
+	}
+	word := l.src[start:end]
+	if end < len(l.src) {
+		next := l.src[end]
+		switch {
+		case next == '"' && isRawPrefix(word):
+			l.advance(end - start)
+			l.lexRawString(start, line, col)
+			return
+		case (next == '"' || next == '\'') && isEncodingPrefix(word):
+			l.advance(end - start)
+			kind := TokenString
+			if next == '\'' {
+				kind = TokenChar
+			}
+			l.lexQuoted(start, line, col, next, kind)
+			return
+		}
+	}
+	l.advance(end - start)
+	l.emit(TokenIdent, start, line, col)
+}
+
+// lexRawString 读取原始字符串 R"delim(...)delim"，当前位置指向开头的引号
+func (l *lexer) lexRawString(start, line, col int) {
+	l.advance(1)
+	delimStart := l.pos
+	for l.pos < len(l.src) && l.src[l.pos] != '(' && l.src[l.pos] != '\n' && l.pos-delimStart <= 16 {
+		l.advance(1)
+	}
+	closing := ")" + l.src[delimStart:l.pos] + "\""
+	idx := strings.Index(l.src[l.pos:], closing)
+	if idx < 0 {
+		l.advance(len(l.src) - l.pos)
+	} else {
+		l.advance(idx + len(closing))
+	}
+	l.emit(TokenString, start, line, col)
+}
+
+// lexQuoted 读取普通字符串或字符字面量，当前位置指向开头的引号
+func (l *lexer) lexQuoted(start, line, col int, quote byte, kind TokenKind) {
+	l.skipQuoted(quote)
+	// 用户自定义字面量后缀，如 "abc"s
+	for l.pos < len(l.src) && isIdentChar(l.src[l.pos]) {
+		l.advance(1)
+	}
+	l.emit(kind, start, line, col)
+}
+
+// skipQuoted 跳过以quote包围的字面量，字面量在行尾未闭合时停止
+func (l *lexer) skipQuoted(quote byte) {
+	l.advance(1)
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		if c == '\\' {
+			l.advance(2)
+			continue
+		}
+		if c == '\n' {
+			return
+		}
+		l.advance(1)
+		if c == quote {
+			return
+		}
+	}
+}
+
+// lexNumber 读取数字字面量(预处理数字)，支持数字分隔符和指数
+func (l *lexer) lexNumber() {
+	start, line, col := l.pos, l.line, l.col
+	l.advance(1)
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case (c == '+' || c == '-') && strings.ContainsRune("eEpP", rune(l.src[l.pos-1])):
+			l.advance(1)
+		case c == '\'' && isIdentChar(l.peek(1)):
+			l.advance(1)
+		case isIdentChar(c) || c == '.':
+			l.advance(1)
+		default:
+			l.emit(TokenNumber, start, line, col)
+			return
+		}
+	}
+	l.emit(TokenNumber, start, line, col)
+}
+
+// lexPunct 读取运算符或标点
+func (l *lexer) lexPunct() {
+	start, line, col := l.pos, l.line, l.col
+	for _, p := range punctuators {
+		if strings.HasPrefix(l.src[l.pos:], p) {
+			l.advance(len(p))
+			l.emit(TokenPunct, start, line, col)
+			return
+		}
+	}
+	l.advance(1)
+	l.emit(TokenPunct, start, line, col)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+// isEncodingPrefix 判断是否为字符串/字符字面量的编码前缀
+func isEncodingPrefix(s string) bool {
+	return s == "L" || s == "u" || s == "U" || s == "u8"
+}
+
+// isRawPrefix 判断是否为原始字符串前缀
+func isRawPrefix(s string) bool {
+	return s == "R" || s == "LR" || s == "uR" || s == "UR" || s == "u8R"
+}
@@ -0,0 +1,62 @@
+package analyzer
+
+import (
+	"testing"
+)
+
+// TestTokenize_Literals 测试字符串、字符和原始字符串字面量
+func TestTokenize_Literals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`s = "class X {";`, []string{"s", "=", `"class X {"`, ";"}},
+		{`c = '{';`, []string{"c", "=", `'{'`, ";"}},
+		{`s = "a\"b // c";`, []string{"s", "=", `"a\"b // c"`, ";"}},
+		{`s = u8"x" L'y';`, []string{"s", "=", `u8"x"`, `L'y'`, ";"}},
+		{"s = R\"(class A { \" )\";", []string{"s", "=", "R\"(class A { \" )\"", ";"}},
+		{"s = R\"xy(a)\" )xy\";", []string{"s", "=", "R\"xy(a)\" )xy\"", ";"}},
+		{`n = 1'000'000 + 0x1Fp-3;`, []string{"n", "=", "1'000'000", "+", "0x1Fp-3", ";"}},
+		{`a::b->c >>= 2;`, []string{"a", "::", "b", "->", "c", ">>=", "2", ";"}},
+	}
+
+	for _, test := range tests {
+		var result []string
+		for _, tok := range Tokenize(test.input) {
+			result = append(result, tok.Text)
+		}
+		if !sliceEqual(result, test.expected) {
+			t.Errorf("词法分析 '%s' 失败，期望: %q，实际: %q", test.input, test.expected, result)
+		}
+	}
+}
+
+// TestTokenize_Directives 测试预处理指令和行列号
+func TestTokenize_Directives(t *testing.T) {
+	src := "#include \"a.h\" // 注释\n" +
+		"  #define MAX(a, b) \\\n    ((a) > (b))\n" +
+		"class /* # */ A {};\n" +
+		"x = a # b;\n"
+
+	tokens := Tokenize(src)
+	if len(tokens) < 2 || tokens[0].Kind != TokenDirective || tokens[1].Kind != TokenDirective {
+		t.Fatalf("期望前两个词法单元为预处理指令，实际: %+v", tokens)
+	}
+	if tokens[0].Text != `#include "a.h"` {
+		t.Errorf("#include 指令内容不正确: %q", tokens[0].Text)
+	}
+	if tokens[1].Text != "#define MAX(a, b)      ((a) > (b))" {
+		t.Errorf("续行指令内容不正确: %q", tokens[1].Text)
+	}
+	if tokens[2].Text != "class" || tokens[2].Line != 4 || tokens[2].Column != 1 {
+		t.Errorf("class 的位置不正确: %+v", tokens[2])
+	}
+	if tokens[3].Text != "A" || tokens[3].Column != 15 {
+		t.Errorf("A 的位置不正确: %+v", tokens[3])
+	}
+	for _, tok := range tokens[2:] {
+		if tok.Kind == TokenDirective {
+			t.Errorf("行中间的 # 不应被识别为预处理指令: %+v", tok)
+		}
+	}
+}
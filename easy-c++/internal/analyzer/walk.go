@@ -0,0 +1,291 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// globRule 一条文件匹配规则，语法与 .gitignore 相同:
+// 不含 / 的模式匹配任意层级的文件名或目录名，如 build、*.pb.h；
+// 含 / 的模式从所在目录开始匹配，如 src/gen/*.h、/third_party；
+// ** 匹配任意层目录，如 **/generated/**；以 / 结尾的模式只匹配目录
+type globRule struct {
+	base     string // 规则所在的目录，相对于项目根目录，根目录为空
+	pattern  string // 去掉开头和结尾的 / 之后的模式
+	anchored bool   // 是否从 base 开始匹配整个路径
+	dirOnly  bool   // 是否只匹配目录
+	negate   bool   // .gitignore 中以 ! 开头的规则，重新包含之前忽略的文件
+}
+
+// parseGlobRule 解析一条规则，base 为规则所在的目录
+func parseGlobRule(base, pattern string) (globRule, error) {
+	rule := globRule{base: base}
+	if strings.HasPrefix(pattern, "!") {
+		rule.negate = true
+		pattern = pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "/") {
+		rule.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	rule.anchored = strings.Contains(pattern, "/")
+	rule.pattern = strings.TrimPrefix(pattern, "/")
+	if rule.pattern == "" {
+		return rule, fmt.Errorf("无效的匹配模式: %q", pattern)
+	}
+	for _, segment := range strings.Split(rule.pattern, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return rule, fmt.Errorf("无效的匹配模式 %q: %v", pattern, err)
+		}
+	}
+	return rule, nil
+}
+
+// matches 判断相对于项目根目录的路径 rel (以 / 分隔) 是否匹配规则
+func (r globRule) matches(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.base != "" {
+		sub, ok := strings.CutPrefix(rel, r.base+"/")
+		if !ok {
+			return false
+		}
+		rel = sub
+	}
+	if !r.anchored {
+		return matchSegments([]string{r.pattern}, []string{path.Base(rel)})
+	}
+	return matchSegments(strings.Split(r.pattern, "/"), strings.Split(rel, "/"))
+}
+
+// matchSegments 逐段匹配路径，** 匹配零个或多个目录
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// fileFilter 项目分析时选择文件的规则
+type fileFilter struct {
+	root      string     // 项目根目录
+	includes  []globRule // --include: 不为空时只从匹配的文件开始分析
+	excludes  []globRule // --exclude: 排除匹配的文件和目录
+	gitignore []globRule // 遍历时读取的 .gitignore 规则，按读取顺序排列
+}
+
+// rel 返回 path 相对于项目根目录的路径，path 不在项目目录中时 ok 为 false
+func (f *fileFilter) rel(filePath string) (rel string, ok bool) {
+	rel, err := filepath.Rel(f.root, filePath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// excluded 判断相对路径 rel 本身是否被 --exclude 或 .gitignore 排除，不检查其所在的目录
+func (f *fileFilter) excluded(rel string, isDir bool) bool {
+	for _, rule := range f.excludes {
+		if rule.matches(rel, isDir) {
+			return true
+		}
+	}
+	// .gitignore 中后面的规则优先
+	ignored := false
+	for _, rule := range f.gitignore {
+		if rule.matches(rel, isDir) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// skips 判断通过 #include 找到的文件是否被排除，所在的目录被排除时文件也被排除
+// 项目目录之外的文件不受项目规则的限制
+func (f *fileFilter) skips(filePath string) bool {
+	if f == nil {
+		return false
+	}
+	rel, ok := f.rel(filePath)
+	if !ok || rel == "." {
+		return false
+	}
+	segments := strings.Split(rel, "/")
+	for i := range segments {
+		if f.excluded(strings.Join(segments[:i+1], "/"), i < len(segments)-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// selected 判断遍历到的文件是否作为分析的起点
+func (f *fileFilter) selected(rel string) bool {
+	if len(f.includes) == 0 {
+		return true
+	}
+	for _, rule := range f.includes {
+		if rule.matches(rel, false) {
+			return true
+		}
+	}
+	return false
+}
+
+// addGitignore 添加 .gitignore 中的规则，rel 为其所在目录相对于项目根目录的路径
+func (f *fileFilter) addGitignore(rel string, content []byte) {
+	if rel == "." {
+		rel = ""
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if rule, err := parseGlobRule(rel, line); err == nil {
+			f.gitignore = append(f.gitignore, rule)
+		}
+	}
+}
+
+// IncludeFiles 只从匹配 pattern 的文件开始分析项目，可多次调用，匹配任意一个即可
+// 这些文件通过 #include 包含的头文件仍然参与分析。模式的语法与 .gitignore 相同，相对于项目根目录
+func (a *CppAnalyzer) IncludeFiles(pattern string) error {
+	rule, err := parseGlobRule("", pattern)
+	if err != nil {
+		return err
+	}
+	a.includeRules = append(a.includeRules, rule)
+	return nil
+}
+
+// ExcludeFiles 分析项目时排除匹配 pattern 的文件和目录，可多次调用
+// 被排除的文件即使被 #include 也不分析，其中的类被引用为基类时作为外部基类
+func (a *CppAnalyzer) ExcludeFiles(pattern string) error {
+	rule, err := parseGlobRule("", pattern)
+	if err != nil {
+		return err
+	}
+	a.excludeRules = append(a.excludeRules, rule)
+	return nil
+}
+
+// SetGitignore 设置分析项目时是否遵循各目录中的 .gitignore
+func (a *CppAnalyzer) SetGitignore(enabled bool) {
+	a.useGitignore = enabled
+}
+
+// walkProject 遍历项目目录，返回作为分析起点的C++文件
+// 跟随指向目录的符号链接，同一个目录只遍历一次；形成循环的符号链接报告警告后跳过。
+// 无法访问的子目录记入 failed，根目录无法访问时返回错误
+func (a *CppAnalyzer) walkProject(ctx context.Context, root string) (paths []string, failed []*FileError, err error) {
+	info, err := a.statSource(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !info.IsDir() {
+		if a.isCppFile(root) {
+			paths = append(paths, root)
+		}
+		return paths, nil, nil
+	}
+	realRoot, err := a.realPath(root)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	visited := map[string]bool{realRoot: true}
+	var walk func(dir string, ancestors map[string]bool) error
+	walk = func(dir string, ancestors map[string]bool) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, _ := a.filter.rel(dir)
+		if a.useGitignore {
+			if content, err := a.readSource(filepath.Join(dir, ".gitignore")); err == nil {
+				a.filter.addGitignore(rel, content)
+			}
+		}
+		entries, err := a.readSourceDir(dir)
+		if err != nil {
+			if dir == root {
+				return err
+			}
+			a.report(SeverityError, CodeFileRead, dir, 0, 0, "%v", err)
+			failed = append(failed, &FileError{Path: dir, Err: err})
+			if a.failFast {
+				return filepath.SkipAll
+			}
+			return nil
+		}
+
+		for _, entry := range entries {
+			entryPath := filepath.Join(dir, entry.Name())
+			entryRel, _ := a.filter.rel(entryPath)
+			isDir := entry.IsDir()
+			if entry.Type()&os.ModeSymlink != 0 {
+				// 无法解析的符号链接按文件处理，读取时报告错误
+				if target, err := a.statSource(entryPath); err == nil {
+					isDir = target.IsDir()
+				}
+			}
+			if (isDir && entry.Name() == ".git") || a.filter.excluded(entryRel, isDir) {
+				continue
+			}
+			if !isDir {
+				if a.filter.selected(entryRel) && a.isCppFile(entryPath) {
+					paths = append(paths, entryPath)
+				}
+				continue
+			}
+
+			realDir, err := a.realPath(entryPath)
+			if err != nil {
+				continue
+			}
+			if ancestors[realDir] {
+				a.report(SeverityWarning, CodeSymlinkLoop, entryPath, 0, 0, "符号链接指向其所在的上级目录 %s，已跳过", realDir)
+				continue
+			}
+			if visited[realDir] {
+				// 已经通过其他路径遍历过
+				continue
+			}
+			visited[realDir] = true
+			inner := make(map[string]bool, len(ancestors)+1)
+			for ancestor := range ancestors {
+				inner[ancestor] = true
+			}
+			inner[realDir] = true
+			if err := walk(entryPath, inner); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	err = walk(root, map[string]bool{realRoot: true})
+	if err == filepath.SkipAll {
+		err = nil
+	}
+	return paths, failed, err
+}
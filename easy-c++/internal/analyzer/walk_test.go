@@ -0,0 +1,101 @@
+package analyzer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestProjectFilters 测试分析项目时的 --include/--exclude、.gitignore 和符号链接循环
+func TestProjectFilters(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		".gitignore":               "build/\n*.gen.h\n!keep.gen.h\n",
+		"src/widget.h":             "#include \"../third_party/lib/base.h\"\nclass Widget : public lib::Base {};\n",
+		"src/widget.cpp":           "#include \"widget.h\"\n",
+		"src/proto/msg.pb.h":       "class Msg {};\n",
+		"src/table.gen.h":          "class Table {};\n",
+		"src/keep.gen.h":           "class Keep {};\n",
+		"third_party/lib/base.h":   "namespace lib { class Base {}; }\n",
+		"third_party/lib/extra.h":  "namespace lib { class Extra {}; }\n",
+		"build/generated/config.h": "class Config {};\n",
+		"tools/.gitignore":         "!*.gen.h\n",
+		"tools/script.gen.h":       "class Script {};\n",
+		".git/hooks/precommit.h":   "class Hook {};\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("创建目录失败: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+	}
+	// 指向上级目录的符号链接
+	if err := os.Symlink("..", filepath.Join(tempDir, "src", "parent")); err != nil {
+		t.Skipf("无法创建符号链接: %v", err)
+	}
+
+	analyze := func(setup func(a *CppAnalyzer)) (map[string]*CppClass, Diagnostics) {
+		a := NewCppAnalyzer()
+		setup(a)
+		classes, err := a.AnalyzeProject(tempDir)
+		if err != nil {
+			t.Fatalf("项目分析失败: %v", err)
+		}
+		found := make(map[string]*CppClass)
+		for _, class := range classes {
+			if !class.External {
+				found[class.QualifiedName] = class
+			}
+		}
+		return found, a.Diagnostics()
+	}
+	expect := func(found map[string]*CppClass, names ...string) {
+		t.Helper()
+		if len(found) != len(names) {
+			t.Errorf("期望 %d 个类 %v，实际: %v", len(names), names, found)
+		}
+		for _, name := range names {
+			if found[name] == nil {
+				t.Errorf("缺少类 %s", name)
+			}
+		}
+	}
+
+	// 默认跳过 .git，其余目录都遍历，符号链接循环报告警告
+	found, diagnostics := analyze(func(a *CppAnalyzer) {})
+	expect(found, "Widget", "Msg", "Table", "Keep", "lib::Base", "lib::Extra", "Config", "Script")
+	if len(diagnostics) != 1 || diagnostics[0].Code != CodeSymlinkLoop {
+		t.Errorf("应报告 1 个符号链接循环，实际: %v", diagnostics)
+	}
+
+	found, _ = analyze(func(a *CppAnalyzer) { a.SetGitignore(true) })
+	expect(found, "Widget", "Msg", "Keep", "lib::Base", "lib::Extra", "Script")
+
+	// 被排除的文件即使被包含也不分析，其中的类作为外部基类
+	found, _ = analyze(func(a *CppAnalyzer) {
+		for _, pattern := range []string{"third_party/", "build", "**/*.pb.h", "*.gen.h"} {
+			if err := a.ExcludeFiles(pattern); err != nil {
+				t.Fatalf("无效的模式 %s: %v", pattern, err)
+			}
+		}
+	})
+	expect(found, "Widget")
+	if base := found["Widget"].BaseClasses[0]; base.Name != "lib::Base" || !base.External {
+		t.Errorf("被排除的基类应作为外部基类，实际: %+v", base)
+	}
+
+	// 只从 src 下的源文件开始分析，被包含的头文件仍然参与分析
+	found, _ = analyze(func(a *CppAnalyzer) {
+		if err := a.IncludeFiles("src/*.cpp"); err != nil {
+			t.Fatalf("无效的模式: %v", err)
+		}
+	})
+	expect(found, "Widget", "lib::Base")
+
+	if err := NewCppAnalyzer().ExcludeFiles("src/[a-"); err == nil {
+		t.Error("无效的模式应返回错误")
+	}
+}
@@ -2,25 +2,84 @@ package visualizer
 
 import (
 	"fmt"
+	"html"
+	"sort"
 	"strings"
 )
 
 // HTMLGenerator generates interactive HTML diagrams for class inheritance
 type HTMLGenerator struct {
-	classes map[string]*HTMLClass
+	classes  map[string]*HTMLClass
+	includes []*HTMLIncludeFile
 }
 
 // HTMLClass represents a class for HTML visualization
 type HTMLClass struct {
 	Name     string
-	Members  []string
-	Methods  []string
-	Parents  []string
+	Template string // Template header such as template<typename T>, empty for non-templates
+	Primary  string // Primary template of a specialization
+	Members  []HTMLMember
+	Methods  []HTMLMember
+	Parents  []HTMLParent
 	Children []string
+
+	Kind         string   // concrete, abstract or interface
+	ClassKey     string   // class, struct or union
+	Implementers []string // Non-interface classes deriving from an interface
+	EnclosedBy   string   // Enclosing class of a nested class, or enclosing function of a local class
+	Nested       []string // Classes nested directly inside this class
+	External     bool     // Referenced as a base but not defined in the analyzed files, e.g. std::runtime_error
+	Catalog      string   // Hierarchy catalog describing an external class, e.g. std
+
+	Undeclared []HTMLMember // Out-of-line method definitions with no declaration in the class
+	Conflicts  []string     // Locations of other, different definitions of the same class
+
 	Level    int
 	FilePath string
 }
 
+// HTMLMember describes a member variable or method of a class
+type HTMLMember struct {
+	Access      string   // Access level: public, protected or private
+	Declaration string   // Declaration as written, e.g. static constexpr int kMax = 10
+	Tags        []string // Markers shown after the declaration, e.g. constructor
+	Location    string   // Where a method is defined out of line, e.g. circle.cpp:12
+}
+
+// HTMLParent describes the inheritance edge from a class to one of its parents
+type HTMLParent struct {
+	Name     string   // Name of the parent class, used for linking
+	Label    string   // Text shown for the edge, e.g. Base<Derived>
+	Access   string   // Inheritance access: public, protected or private
+	Virtual  bool     // Virtual inheritance, drawn as a dashed edge
+	Tags     []string // Markers shown next to the edge, e.g. CRTP
+	External bool     // The parent is not defined in the analyzed files
+}
+
+// Description returns the edge as written in a base clause, e.g. virtual public Base
+func (p HTMLParent) Description() string {
+	s := p.Label
+	if p.Access != "" {
+		s = p.Access + " " + s
+	}
+	if p.Virtual {
+		s = "virtual " + s
+	}
+	return s
+}
+
+// HTMLIncludeFile lists the #include directives of one analyzed file
+type HTMLIncludeFile struct {
+	File     string
+	Includes []HTMLInclude
+}
+
+// HTMLInclude describes a single #include directive
+type HTMLInclude struct {
+	Name   string // Header as written, e.g. "shape.h" or <vector>
+	Target string // Resolved file path, empty when the header was not found
+}
+
 // NewHTMLGenerator creates a new HTML generator
 func NewHTMLGenerator() *HTMLGenerator {
 	return &HTMLGenerator{
@@ -29,23 +88,22 @@ func NewHTMLGenerator() *HTMLGenerator {
 }
 
 // AddClass adds a class to the HTML diagram
-func (h *HTMLGenerator) AddClass(name string, members, methods, parents []string, filePath string) {
-	h.classes[name] = &HTMLClass{
-		Name:     name,
-		Members:  members,
-		Methods:  methods,
-		Parents:  parents,
-		Children: []string{},
-		FilePath: filePath,
-	}
+func (h *HTMLGenerator) AddClass(class *HTMLClass) {
+	class.Children = []string{}
+	h.classes[class.Name] = class
+}
+
+// AddIncludeFile adds a file of the include graph, shown next to the class hierarchy
+func (h *HTMLGenerator) AddIncludeFile(file *HTMLIncludeFile) {
+	h.includes = append(h.includes, file)
 }
 
 // calculateRelationships builds parent-child relationships
 func (h *HTMLGenerator) calculateRelationships() {
 	// Build children relationships
 	for className, class := range h.classes {
-		for _, parentName := range class.Parents {
-			if parent, exists := h.classes[parentName]; exists {
+		for _, p := range class.Parents {
+			if parent, exists := h.classes[p.Name]; exists {
 				parent.Children = append(parent.Children, className)
 			}
 		}
@@ -56,9 +114,9 @@ func (h *HTMLGenerator) calculateRelationships() {
 }
 
 // calculateLevels determines the inheritance level of each class
-func (h *HTMLGenerator) calculateLevels() { // Find root classes (classes with no parents)
+func (h *HTMLGenerator) calculateLevels() { // Find root classes (classes with no known parents)
 	for _, class := range h.classes {
-		if len(class.Parents) == 0 {
+		if !h.hasKnownParent(class) {
 			class.Level = 0
 		} else {
 			class.Level = -1 // Mark as unprocessed
@@ -77,8 +135,8 @@ func (h *HTMLGenerator) calculateLevels() { // Find root classes (classes with n
 			maxParentLevel := -1
 			allParentsProcessed := true
 
-			for _, parentName := range class.Parents {
-				if parent, exists := h.classes[parentName]; exists {
+			for _, p := range class.Parents {
+				if parent, exists := h.classes[p.Name]; exists {
 					if parent.Level >= 0 {
 						if parent.Level > maxParentLevel {
 							maxParentLevel = parent.Level
@@ -105,6 +163,29 @@ func (h *HTMLGenerator) calculateLevels() { // Find root classes (classes with n
 	}
 }
 
+// hasKnownParent reports whether any parent of the class is part of the diagram
+func (h *HTMLGenerator) hasKnownParent(class *HTMLClass) bool {
+	for _, p := range class.Parents {
+		if _, exists := h.classes[p.Name]; exists {
+			return true
+		}
+	}
+	return false
+}
+
+// parentLabels returns the display text of every parent, with its tags appended
+func parentLabels(parents []HTMLParent) []string {
+	var labels []string
+	for _, p := range parents {
+		label := p.Description()
+		for _, tag := range p.Tags {
+			label += " [" + tag + "]"
+		}
+		labels = append(labels, label)
+	}
+	return labels
+}
+
 // GenerateHTML generates the complete interactive HTML content
 func (h *HTMLGenerator) GenerateHTML() string {
 	h.calculateRelationships()
@@ -237,6 +318,44 @@ func (h *HTMLGenerator) generateHTMLHeader() string {
             box-shadow: 0 5px 15px rgba(52, 152, 219, 0.2);
         }
         
+        .class-node.kind-abstract {
+            border-style: dashed;
+            border-color: #e67e22;
+        }
+        
+        .class-node.kind-interface {
+            border-style: double;
+            border-width: 4px;
+            border-color: #8e44ad;
+            background: #f8f4fb;
+        }
+        
+        .class-kind {
+            float: right;
+            font-size: 0.75em;
+            padding: 2px 8px;
+            border-radius: 10px;
+            background: #ecf0f1;
+            color: #7f8c8d;
+        }
+        
+        .kind-abstract .class-kind {
+            background: #fdebd0;
+            color: #e67e22;
+        }
+        
+        .kind-interface .class-kind {
+            background: #e8daef;
+            color: #8e44ad;
+        }
+        
+        .class-node.external {
+            border-style: dotted;
+            border-color: #95a5a6;
+            background: #f4f6f6;
+            color: #7f8c8d;
+        }
+        
         .class-node.selected {
             border-color: #e74c3c;
             background: linear-gradient(45deg, #e74c3c, #c0392b);
@@ -268,6 +387,14 @@ func (h *HTMLGenerator) generateHTMLHeader() string {
             color: #27ae60;
         }
         
+        .nested {
+            color: #16a085;
+        }
+        
+        .conflict {
+            color: #c0392b;
+        }
+        
         .children {
             color: #8e44ad;
         }
@@ -345,6 +472,25 @@ func (h *HTMLGenerator) generateHTMLHeader() string {
             content: "⚡";
         }
         
+        .member-access {
+            display: inline-block;
+            min-width: 64px;
+            font-size: 0.8em;
+            color: #7f8c8d;
+        }
+        
+        .member-access.public {
+            color: #27ae60;
+        }
+        
+        .member-access.protected {
+            color: #e67e22;
+        }
+        
+        .member-access.private {
+            color: #c0392b;
+        }
+        
         .inheritance-list {
             display: flex;
             flex-wrap: wrap;
@@ -366,6 +512,49 @@ func (h *HTMLGenerator) generateHTMLHeader() string {
             transform: scale(1.05);
         }
         
+        .inheritance-item.virtual {
+            background: white;
+            color: #2980b9;
+            border: 2px dashed #3498db;
+        }
+        
+        .inheritance-item.virtual:hover {
+            background: #ebf5fb;
+        }
+        
+        .inheritance-item.external {
+            background: #95a5a6;
+        }
+        
+        .access {
+            opacity: 0.8;
+            font-size: 0.85em;
+            margin-right: 4px;
+        }
+        
+        .tag {
+            display: inline-block;
+            background: #f39c12;
+            color: white;
+            padding: 0 6px;
+            margin-left: 4px;
+            border-radius: 8px;
+            font-size: 0.8em;
+        }
+
+        .location {
+            margin-left: 6px;
+            color: #7f8c8d;
+            font-size: 0.85em;
+            font-family: monospace;
+        }
+        
+        .class-template {
+            font-family: monospace;
+            font-size: 0.85em;
+            color: #16a085;
+        }
+        
         .children-item {
             background: #8e44ad;
         }
@@ -396,6 +585,47 @@ func (h *HTMLGenerator) generateHTMLHeader() string {
             color: #e74c3c;
             font-size: 1.2em;
         }
+        
+        .interface-summary {
+            background: #f8f4fb;
+            padding: 15px;
+            border-radius: 8px;
+            margin-top: 20px;
+        }
+        
+        .interface-entry {
+            margin: 8px 0;
+        }
+        
+        .interface-name {
+            font-weight: bold;
+            color: #8e44ad;
+            margin-right: 6px;
+        }
+        
+        .include-summary {
+            background: #fdf6e3;
+            padding: 15px;
+            border-radius: 8px;
+            margin-top: 20px;
+            font-family: monospace;
+            font-size: 0.9em;
+        }
+        
+        .include-file {
+            font-weight: bold;
+            margin-top: 6px;
+        }
+        
+        .include-edge {
+            padding-left: 15px;
+            color: #555;
+        }
+        
+        .include-edge.missing {
+            color: #aaa;
+            font-style: italic;
+        }
     </style>
 </head>
 <body>
@@ -438,23 +668,63 @@ func (h *HTMLGenerator) generateTreeView() string {
 `, levelName, len(classes)))
 
 			for _, class := range classes {
-				sb.WriteString(fmt.Sprintf(`                    <div class="class-node" onclick="showClassDetails('%s')">
-                        <div class="class-name">%s</div>
+				sb.WriteString(fmt.Sprintf(`                    <div class="class-node%s" onclick="showClassDetails('%s')">
+`, nodeClass(class), html.EscapeString(class.Name)))
+				if class.External {
+					sb.WriteString(fmt.Sprintf(`                        <div class="class-kind">%s</div>
+`, html.EscapeString(externalKindLabel(class))))
+				} else if class.Kind != "" {
+					sb.WriteString(fmt.Sprintf(`                        <div class="class-kind">%s</div>
+`, kindLabel(class.Kind)))
+				}
+				if class.Template != "" {
+					sb.WriteString(fmt.Sprintf(`                        <div class="class-template">%s</div>
+`, html.EscapeString(class.Template)))
+				}
+				sb.WriteString(fmt.Sprintf(`                        <div class="class-name">%s</div>
                         <div class="class-file">📁 %s</div>
-`, class.Name, class.Name, class.FilePath))
+`, html.EscapeString(class.Name), html.EscapeString(classLocation(class))))
 
 				if len(class.Parents) > 0 {
 					sb.WriteString(fmt.Sprintf(`                        <div class="inheritance-info parents">
                             ⬆️ 继承自: %s
                         </div>
-`, strings.Join(class.Parents, ", ")))
+`, html.EscapeString(strings.Join(parentLabels(class.Parents), ", "))))
+				}
+
+				if class.Primary != "" {
+					sb.WriteString(fmt.Sprintf(`                        <div class="inheritance-info parents">
+                            📐 特化自: %s
+                        </div>
+`, html.EscapeString(class.Primary)))
+				}
+
+				if class.EnclosedBy != "" {
+					sb.WriteString(fmt.Sprintf(`                        <div class="inheritance-info nested">
+                            📦 嵌套于: %s
+                        </div>
+`, html.EscapeString(class.EnclosedBy)))
+				}
+
+				if len(class.Nested) > 0 {
+					sb.WriteString(fmt.Sprintf(`                        <div class="inheritance-info nested">
+                            🗂️ 嵌套类: %s
+                        </div>
+`, html.EscapeString(strings.Join(class.Nested, ", "))))
+				}
+
+				if len(class.Conflicts) > 0 {
+					sb.WriteString(fmt.Sprintf(`                        <div class="inheritance-info conflict">
+                            ⚠️ 定义冲突: 另有不同的定义位于 %s
+                        </div>
+`, html.EscapeString(strings.Join(class.Conflicts, ", "))))
 				}
 
 				if len(class.Children) > 0 {
 					sb.WriteString(fmt.Sprintf(`                        <div class="inheritance-info children">
                             ⬇️ 子类: %s
                         </div>
-`, strings.Join(class.Children, ", ")))
+`, html.EscapeString(strings.Join(class.Children, ", "))))
 				}
 
 				sb.WriteString("                    </div>\n")
@@ -464,23 +734,105 @@ func (h *HTMLGenerator) generateTreeView() string {
 		}
 	}
 
-	// Add statistics
-	totalClasses := len(h.classes)
-	rootClasses := len(levelGroups[0])
+	// Add statistics, external classes are counted separately
+	totalClasses, rootClasses, externalClasses := 0, 0, 0
+	for _, class := range h.classes {
+		switch {
+		case class.External:
+			externalClasses++
+		case class.Level == 0:
+			rootClasses++
+			totalClasses++
+		default:
+			totalClasses++
+		}
+	}
 	maxDepth := maxLevel
 
 	sb.WriteString(fmt.Sprintf(`                <div class="stats">
                     <div class="stat-item">📈 总类数: <span class="stat-number">%d</span></div>
                     <div class="stat-item">🌳 根类数: <span class="stat-number">%d</span></div>
                     <div class="stat-item">📏 最大深度: <span class="stat-number">%d</span></div>
-                </div>
 `, totalClasses, rootClasses, maxDepth))
+	if externalClasses > 0 {
+		sb.WriteString(fmt.Sprintf(`                    <div class="stat-item">🔌 外部类数: <span class="stat-number">%d</span></div>
+`, externalClasses))
+	}
+	sb.WriteString(`                </div>
+`)
+
+	sb.WriteString(h.generateInterfaceSummary())
+	sb.WriteString(h.generateIncludeSummary())
 
 	sb.WriteString("            </div>\n            <div class=\"details-panel\">\n")
 
 	return sb.String()
 }
 
+// generateInterfaceSummary lists every interface together with its implementers
+func (h *HTMLGenerator) generateInterfaceSummary() string {
+	var names []string
+	for name, class := range h.classes {
+		if class.Kind == "interface" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`                <div class="interface-summary">
+                    <div class="level-header">🧩 接口与实现类 (%d 个接口)</div>
+`, len(names)))
+	if len(names) == 0 {
+		sb.WriteString(`                    <p class="no-data">未发现纯接口</p>
+`)
+	}
+	for _, name := range names {
+		sb.WriteString(fmt.Sprintf(`                    <div class="interface-entry"><span class="interface-name inheritance-item" data-target="%s">%s</span>`,
+			html.EscapeString(name), html.EscapeString(name)))
+		if len(h.classes[name].Implementers) == 0 {
+			sb.WriteString(`<span class="no-data">暂无实现类</span>`)
+		}
+		for _, impl := range h.classes[name].Implementers {
+			sb.WriteString(fmt.Sprintf(`<span class="inheritance-item children-item" data-target="%s">%s</span> `,
+				html.EscapeString(impl), html.EscapeString(impl)))
+		}
+		sb.WriteString(`</div>
+`)
+	}
+	sb.WriteString(`                </div>
+`)
+	return sb.String()
+}
+
+// generateIncludeSummary lists the include graph file by file, unresolved headers are greyed out
+func (h *HTMLGenerator) generateIncludeSummary() string {
+	if len(h.includes) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`                <div class="include-summary">
+                    <div class="level-header">📂 包含关系 (%d 个文件)</div>
+`, len(h.includes)))
+	for _, file := range h.includes {
+		sb.WriteString(fmt.Sprintf(`                    <div class="include-file">%s</div>
+`, html.EscapeString(file.File)))
+		for _, include := range file.Includes {
+			if include.Target == "" {
+				sb.WriteString(fmt.Sprintf(`                    <div class="include-edge missing">↳ %s (未找到)</div>
+`, html.EscapeString(include.Name)))
+			} else {
+				sb.WriteString(fmt.Sprintf(`                    <div class="include-edge" title="%s">↳ %s</div>
+`, html.EscapeString(include.Target), html.EscapeString(include.Name)))
+			}
+		}
+	}
+	sb.WriteString(`                </div>
+`)
+	return sb.String()
+}
+
 // generateClassCards generates detailed cards for each class
 func (h *HTMLGenerator) generateClassCards() string {
 	var sb strings.Builder
@@ -508,14 +860,78 @@ func (h *HTMLGenerator) generateClassCards() string {
 		sb.WriteString(fmt.Sprintf(`                <div id="card-%s" class="class-card">
                     <div class="card-header">🎯 %s</div>
                     <div class="card-content">
-`, class.Name, class.Name))
+`, html.EscapeString(class.Name), html.EscapeString(class.Name)))
 
 		// File info
 		sb.WriteString(fmt.Sprintf(`                        <div class="section">
                             <div class="section-title">📁 文件位置</div>
                             <p>%s</p>
                         </div>
-`, class.FilePath))
+`, html.EscapeString(classLocation(class))))
+
+		// Kind
+		if class.Kind != "" {
+			sb.WriteString(fmt.Sprintf(`                        <div class="section">
+                            <div class="section-title">🧩 类别</div>
+                            <p>%s <code>%s</code></p>
+`, kindLabel(class.Kind), html.EscapeString(class.ClassKey)))
+			if len(class.Implementers) > 0 {
+				sb.WriteString(`                            <div class="inheritance-list">实现类: 
+`)
+				for _, impl := range class.Implementers {
+					sb.WriteString(fmt.Sprintf(`                                <span class="inheritance-item children-item" data-target="%s">%s</span>
+`, html.EscapeString(impl), html.EscapeString(impl)))
+				}
+				sb.WriteString(`                            </div>
+`)
+			}
+			sb.WriteString(`                        </div>
+`)
+		}
+
+		// Template info
+		if class.Template != "" || class.Primary != "" {
+			sb.WriteString(`                        <div class="section">
+                            <div class="section-title">📐 模板</div>
+`)
+			if class.Template != "" {
+				sb.WriteString(fmt.Sprintf(`                            <p class="class-template">%s</p>
+`, html.EscapeString(class.Template)))
+			}
+			if class.Primary != "" {
+				sb.WriteString(fmt.Sprintf(`                            <div class="inheritance-list">特化自: <span class="inheritance-item" data-target="%s">%s</span></div>
+`, html.EscapeString(class.Primary), html.EscapeString(class.Primary)))
+			}
+			sb.WriteString(`                        </div>
+`)
+		}
+
+		// Nesting
+		if class.EnclosedBy != "" || len(class.Nested) > 0 {
+			sb.WriteString(`                        <div class="section">
+                            <div class="section-title">📦 嵌套关系</div>
+`)
+			if _, known := h.classes[class.EnclosedBy]; known {
+				sb.WriteString(fmt.Sprintf(`                            <div class="inheritance-list">嵌套于: <span class="inheritance-item" data-target="%s">%s</span></div>
+`, html.EscapeString(class.EnclosedBy), html.EscapeString(class.EnclosedBy)))
+			} else if class.EnclosedBy != "" {
+				// Local classes are enclosed by a function, which has no card of its own
+				sb.WriteString(fmt.Sprintf(`                            <p>所在函数: %s</p>
+`, html.EscapeString(class.EnclosedBy)))
+			}
+			if len(class.Nested) > 0 {
+				sb.WriteString(`                            <div class="inheritance-list">嵌套类: 
+`)
+				for _, nested := range class.Nested {
+					sb.WriteString(fmt.Sprintf(`                                <span class="inheritance-item children-item" data-target="%s">%s</span>
+`, html.EscapeString(nested), html.EscapeString(nested)))
+				}
+				sb.WriteString(`                            </div>
+`)
+			}
+			sb.WriteString(`                        </div>
+`)
+		}
 
 		// Parents
 		sb.WriteString(`                        <div class="section">
@@ -525,8 +941,22 @@ func (h *HTMLGenerator) generateClassCards() string {
 			sb.WriteString(`                            <div class="inheritance-list">
 `)
 			for _, parent := range class.Parents {
-				sb.WriteString(fmt.Sprintf(`                                <span class="inheritance-item" onclick="showClassDetails('%s')">%s</span>
-`, parent, parent))
+				itemClass := "inheritance-item"
+				access := parent.Access
+				if parent.Virtual {
+					itemClass += " virtual"
+					access = "virtual " + access
+				}
+				if parent.External {
+					itemClass += " external"
+				}
+				sb.WriteString(fmt.Sprintf(`                                <span class="%s" data-target="%s"><span class="access">%s</span>%s`,
+					itemClass, html.EscapeString(parent.Name), html.EscapeString(access), html.EscapeString(parent.Label)))
+				for _, tag := range parent.Tags {
+					sb.WriteString(fmt.Sprintf(`<span class="tag">%s</span>`, html.EscapeString(tag)))
+				}
+				sb.WriteString(`</span>
+`)
 			}
 			sb.WriteString(`                            </div>
 `)
@@ -545,8 +975,8 @@ func (h *HTMLGenerator) generateClassCards() string {
 			sb.WriteString(`                            <div class="inheritance-list">
 `)
 			for _, child := range class.Children {
-				sb.WriteString(fmt.Sprintf(`                                <span class="inheritance-item children-item" onclick="showClassDetails('%s')">%s</span>
-`, child, child))
+				sb.WriteString(fmt.Sprintf(`                                <span class="inheritance-item children-item" data-target="%s">%s</span>
+`, html.EscapeString(child), html.EscapeString(child)))
 			}
 			sb.WriteString(`                            </div>
 `)
@@ -557,6 +987,14 @@ func (h *HTMLGenerator) generateClassCards() string {
 		sb.WriteString(`                        </div>
 `)
 
+		// External classes have no known members or methods
+		if class.External {
+			sb.WriteString(`                    </div>
+                </div>
+`)
+			continue
+		}
+
 		// Members
 		sb.WriteString(`                        <div class="section">
                             <div class="section-title">🔧 成员变量</div>
@@ -565,8 +1003,7 @@ func (h *HTMLGenerator) generateClassCards() string {
 			sb.WriteString(`                            <ul class="member-list">
 `)
 			for _, member := range class.Members {
-				sb.WriteString(fmt.Sprintf(`                                <li>%s</li>
-`, member))
+				sb.WriteString(memberItem(member))
 			}
 			sb.WriteString(`                            </ul>
 `)
@@ -585,8 +1022,7 @@ func (h *HTMLGenerator) generateClassCards() string {
 			sb.WriteString(`                            <ul class="member-list method-list">
 `)
 			for _, method := range class.Methods {
-				sb.WriteString(fmt.Sprintf(`                                <li>%s</li>
-`, method))
+				sb.WriteString(memberItem(method))
 			}
 			sb.WriteString(`                            </ul>
 `)
@@ -597,6 +1033,20 @@ func (h *HTMLGenerator) generateClassCards() string {
 		sb.WriteString(`                        </div>
 `)
 
+		// Out-of-line definitions without a declaration
+		if len(class.Undeclared) > 0 {
+			sb.WriteString(`                        <div class="section">
+                            <div class="section-title">⚠️ 未声明的类体外定义</div>
+                            <ul class="member-list method-list">
+`)
+			for _, method := range class.Undeclared {
+				sb.WriteString(memberItem(method))
+			}
+			sb.WriteString(`                            </ul>
+                        </div>
+`)
+		}
+
 		sb.WriteString(`                    </div>
                 </div>
 `)
@@ -647,7 +1097,7 @@ func (h *HTMLGenerator) generateHTMLFooter() string {
             inheritanceItems.forEach(item => {
                 item.addEventListener('click', function(e) {
                     e.stopPropagation();
-                    const className = this.textContent;
+                    const className = this.dataset.target;
                     showClassDetails(className);
                     
                     // Find and scroll to the corresponding node in tree
@@ -664,3 +1114,68 @@ func (h *HTMLGenerator) generateHTMLFooter() string {
 </body>
 </html>`
 }
+
+// kindClass returns the extra CSS class of a class node for its kind
+func kindClass(kind string) string {
+	if kind == "abstract" || kind == "interface" {
+		return " kind-" + kind
+	}
+	return ""
+}
+
+// nodeClass returns the extra CSS classes of a class node in the tree view
+func nodeClass(class *HTMLClass) string {
+	if class.External {
+		return " external"
+	}
+	return kindClass(class.Kind)
+}
+
+// classLocation returns the file shown for a class, external classes have none
+func classLocation(class *HTMLClass) string {
+	if class.External && class.Catalog != "" {
+		return "外部类，来自 " + class.Catalog + " 目录"
+	}
+	if class.External {
+		return "外部类，未在分析的文件中定义"
+	}
+	return class.FilePath
+}
+
+// externalKindLabel returns the badge of an external class node, naming its catalog if any
+func externalKindLabel(class *HTMLClass) string {
+	if class.Catalog != "" {
+		return "外部类 · " + class.Catalog
+	}
+	return "外部类"
+}
+
+// kindLabel returns the display text of a class kind
+func kindLabel(kind string) string {
+	switch kind {
+	case "abstract":
+		return "抽象类"
+	case "interface":
+		return "接口"
+	}
+	return "具体类"
+}
+
+// memberItem renders a member variable or method as a list item
+func memberItem(member HTMLMember) string {
+	var sb strings.Builder
+	sb.WriteString(`                                <li>`)
+	if member.Access != "" {
+		sb.WriteString(fmt.Sprintf(`<span class="member-access %s">%s</span>`, html.EscapeString(member.Access), html.EscapeString(member.Access)))
+	}
+	sb.WriteString(html.EscapeString(member.Declaration))
+	for _, tag := range member.Tags {
+		sb.WriteString(fmt.Sprintf(`<span class="tag">%s</span>`, html.EscapeString(tag)))
+	}
+	if member.Location != "" {
+		sb.WriteString(fmt.Sprintf(`<span class="location" title="定义位置">📍 %s</span>`, html.EscapeString(member.Location)))
+	}
+	sb.WriteString(`</li>
+`)
+	return sb.String()
+}
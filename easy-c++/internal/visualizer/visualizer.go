@@ -20,25 +20,8 @@ func (v *Visualizer) GenerateTextTree(classes []*analyzer.CppClass) string {
 	var sb strings.Builder
 
 	// 构建继承树
-	tree := make(map[string][]*analyzer.CppClass)
-	roots := []*analyzer.CppClass{}
-
-	// 找到所有根类和构建树结构
-	for _, class := range classes {
-		if len(class.BaseClasses) == 0 {
-			roots = append(roots, class)
-		}
-
-		for _, baseClass := range class.BaseClasses {
-			// 查找对应的基类对象
-			for _, bc := range classes {
-				if bc.Name == baseClass {
-					tree[bc.Name] = append(tree[bc.Name], class)
-					break
-				}
-			}
-		}
-	}
+	tree := analyzer.GetInheritanceTree(classes)
+	roots := analyzer.FindRootClasses(classes)
 
 	sb.WriteString("C++ 类继承关系树:\n")
 	sb.WriteString(strings.Repeat("=", 30) + "\n\n")
@@ -59,7 +42,14 @@ func (v *Visualizer) printClassTree(sb *strings.Builder, class *analyzer.CppClas
 		prefix = "📦"
 	}
 
-	sb.WriteString(fmt.Sprintf("%s%s %s", indent, prefix, class.Name))
+	sb.WriteString(fmt.Sprintf("%s%s %s", indent, prefix, class.QualifiedName))
+	if class.External && class.Catalog != "" {
+		sb.WriteString(fmt.Sprintf(" «外部类: %s»", class.Catalog))
+	} else if class.External {
+		sb.WriteString(" «外部类»")
+	} else if class.Kind == analyzer.ClassAbstract || class.Kind == analyzer.ClassInterface {
+		sb.WriteString(fmt.Sprintf(" «%s»", class.Kind.Description()))
+	}
 
 	// 添加成员和方法信息
 	if len(class.Members) > 0 || len(class.Methods) > 0 {
@@ -69,7 +59,7 @@ func (v *Visualizer) printClassTree(sb *strings.Builder, class *analyzer.CppClas
 	sb.WriteString("\n")
 
 	// 递归打印子类
-	if children, exists := tree[class.Name]; exists {
+	if children, exists := tree[class.QualifiedName]; exists {
 		for _, child := range children {
 			v.printClassTree(sb, child, tree, level+1)
 		}
@@ -80,54 +70,53 @@ func (v *Visualizer) printClassTree(sb *strings.Builder, class *analyzer.CppClas
 func (v *Visualizer) GenerateStatistics(classes []*analyzer.CppClass) string {
 	var sb strings.Builder
 
-	totalClasses := len(classes)
-	rootClasses := 0
+	totalClasses := 0
+	externalClasses := 0
 	maxDepth := 0
 	totalMembers := 0
 	totalMethods := 0
+	kindCount := make(map[analyzer.ClassKind]int)
 
-	// 计算统计信息
-	depthMap := make(map[string]int)
-
-	// 找到根类
 	for _, class := range classes {
-		if len(class.BaseClasses) == 0 {
-			rootClasses++
-			depthMap[class.Name] = 0
+		if class.External {
+			externalClasses++
+			continue
 		}
+		totalClasses++
 		totalMembers += len(class.Members)
 		totalMethods += len(class.Methods)
+		kindCount[class.Kind]++
 	}
 
-	// 计算继承深度
-	changed := true
-	for changed {
-		changed = false
-		for _, class := range classes {
-			if _, exists := depthMap[class.Name]; exists {
-				continue
-			}
-
-			maxParentDepth := -1
-			allParentsProcessed := true
-
-			for _, baseClass := range class.BaseClasses {
-				if depth, exists := depthMap[baseClass]; exists {
-					if depth > maxParentDepth {
-						maxParentDepth = depth
-					}
-				} else {
-					allParentsProcessed = false
-					break
-				}
-			}
-
-			if allParentsProcessed && maxParentDepth >= 0 {
-				depthMap[class.Name] = maxParentDepth + 1
-				if maxParentDepth+1 > maxDepth {
-					maxDepth = maxParentDepth + 1
-				}
-				changed = true
+	// 从根类出发逐层计算继承深度
+	tree := analyzer.GetInheritanceTree(classes)
+	roots := analyzer.FindRootClasses(classes)
+	rootClasses := 0
+	for _, root := range roots {
+		if !root.External {
+			rootClasses++
+		}
+	}
+	depthMap := make(map[*analyzer.CppClass]int)
+	queue := roots
+	for _, root := range roots {
+		depthMap[root] = 0
+	}
+	for len(queue) > 0 {
+		class := queue[0]
+		queue = queue[1:]
+		depth := depthMap[class]
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+		// 深度不超过类总数，避免循环继承导致死循环
+		if depth >= len(classes) {
+			continue
+		}
+		for _, child := range tree[class.QualifiedName] {
+			if d, exists := depthMap[child]; !exists || d < depth+1 {
+				depthMap[child] = depth + 1
+				queue = append(queue, child)
 			}
 		}
 	}
@@ -136,7 +125,12 @@ func (v *Visualizer) GenerateStatistics(classes []*analyzer.CppClass) string {
 	sb.WriteString(strings.Repeat("=", 25) + "\n")
 	sb.WriteString(fmt.Sprintf("总类数量: %d\n", totalClasses))
 	sb.WriteString(fmt.Sprintf("根类数量: %d\n", rootClasses))
+	if externalClasses > 0 {
+		sb.WriteString(fmt.Sprintf("外部类数量: %d\n", externalClasses))
+	}
 	sb.WriteString(fmt.Sprintf("最大继承深度: %d\n", maxDepth))
+	sb.WriteString(fmt.Sprintf("接口数量: %d\n", kindCount[analyzer.ClassInterface]))
+	sb.WriteString(fmt.Sprintf("抽象类数量: %d\n", kindCount[analyzer.ClassAbstract]))
 	sb.WriteString(fmt.Sprintf("总成员变量: %d\n", totalMembers))
 	sb.WriteString(fmt.Sprintf("总成员方法: %d\n", totalMethods))
 
@@ -144,5 +138,17 @@ func (v *Visualizer) GenerateStatistics(classes []*analyzer.CppClass) string {
 		sb.WriteString(fmt.Sprintf("平均成员变量/类: %.1f\n", float64(totalMembers)/float64(totalClasses)))
 		sb.WriteString(fmt.Sprintf("平均成员方法/类: %.1f\n", float64(totalMethods)/float64(totalClasses)))
 	}
+
+	// 接口与实现类
+	for _, class := range classes {
+		if class.Kind != analyzer.ClassInterface {
+			continue
+		}
+		var names []string
+		for _, impl := range analyzer.FindImplementers(classes, class) {
+			names = append(names, impl.QualifiedName)
+		}
+		sb.WriteString(fmt.Sprintf("接口 %s 的实现类: %s\n", class.QualifiedName, strings.Join(names, ", ")))
+	}
 	return sb.String()
 }
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -12,67 +14,114 @@ import (
 )
 
 func main() {
+	opts, rest, err := parseOptions(os.Args[1:])
+	if err != nil {
+		fmt.Printf("错误: %v\n", err)
+		os.Exit(1)
+	}
+	args := append([]string{os.Args[0]}, rest...)
+
 	// 检查帮助选项
-	if len(os.Args) >= 2 && (os.Args[1] == "--help" || os.Args[1] == "-h" || os.Args[1] == "help") {
+	if len(args) >= 2 && (args[1] == "--help" || args[1] == "-h" || args[1] == "help") {
 		showHelp()
 		return
 	}
 
-	if len(os.Args) < 2 {
+	if len(args) < 2 {
 		showHelp()
 		os.Exit(1)
 	}
 
 	var classes []*analyzer.CppClass
-	var err error
 	outputFormat := "all" // 默认生成所有格式
 
-	analyzer := analyzer.NewCppAnalyzer()
+	a := analyzer.NewCppAnalyzer()
+	if err := opts.apply(a); err != nil {
+		fmt.Printf("错误: %v\n", err)
+		os.Exit(1)
+	}
 
 	// 解析命令行参数
-	if os.Args[1] == "-project" {
+	if args[1] == "-project" {
 		// 项目目录分析模式
-		if len(os.Args) < 3 {
+		if len(args) < 3 {
 			fmt.Println("错误: 请指定项目目录路径")
 			os.Exit(1)
 		}
-		projectPath := os.Args[2]
-		if len(os.Args) >= 4 {
-			outputFormat = os.Args[3]
+		projectPath := args[2]
+		if len(args) >= 4 {
+			outputFormat = args[3]
 		}
 
 		fmt.Printf("正在分析C++项目目录: %s\n", projectPath)
-		classes, err = analyzer.AnalyzeProject(projectPath)
+		classes, err = analyzeProject(a, projectPath)
+
+	} else if args[1] == "-compile-commands" {
+		// 编译数据库分析模式
+		if len(args) < 3 {
+			fmt.Println("错误: 请指定 compile_commands.json 或其所在目录")
+			os.Exit(1)
+		}
+		databasePath := args[2]
+		if len(args) >= 4 {
+			outputFormat = args[3]
+		}
 
-	} else if os.Args[1] == "-files" {
+		fmt.Printf("正在分析编译数据库: %s\n", databasePath)
+		classes, err = a.AnalyzeCompileCommands(databasePath)
+
+	} else if args[1] == "-files" {
 		// 多文件分析模式
-		if len(os.Args) < 3 {
+		if len(args) < 3 {
 			fmt.Println("错误: 请指定至少一个C++文件")
 			os.Exit(1)
 		}
 		// 查找输出格式参数
 		files := []string{}
-		for i := 2; i < len(os.Args); i++ {
-			arg := os.Args[i]
-			if arg == "text" || arg == "html" || arg == "interactive" || arg == "all" {
+		for i := 2; i < len(args); i++ {
+			arg := args[i]
+			if arg == "text" || arg == "html" || arg == "interactive" || arg == "includes" || arg == "all" {
 				outputFormat = arg
 				break
 			}
+			if arg == "-" {
+				fmt.Println("错误: 标准输入 - 只能作为唯一的输入单独使用，不能出现在 -files 中")
+				os.Exit(1)
+			}
 			files = append(files, arg)
 		}
 
 		fmt.Printf("正在分析C++文件: %v\n", files)
-		classes, err = analyzer.AnalyzeFiles(files)
+		classes, err = a.AnalyzeFiles(files)
+
+	} else if args[1] == "-" {
+		// 从标准输入读取源代码
+		if len(args) >= 3 {
+			outputFormat = args[2]
+		}
+
+		fmt.Println("正在分析标准输入")
+		classes, err = a.AnalyzeReader(stdinName, os.Stdin)
+
+	} else if analyzer.IsArchive(args[1]) {
+		// 源代码压缩包按项目分析
+		archivePath := args[1]
+		if len(args) >= 3 {
+			outputFormat = args[2]
+		}
+
+		fmt.Printf("正在分析源代码压缩包: %s\n", archivePath)
+		classes, err = analyzeProject(a, archivePath)
 
 	} else {
 		// 单文件分析模式
-		filePath := os.Args[1]
-		if len(os.Args) >= 3 {
-			outputFormat = os.Args[2]
+		filePath := args[1]
+		if len(args) >= 3 {
+			outputFormat = args[2]
 		}
 
 		fmt.Printf("正在分析C++文件: %s\n", filePath)
-		classes, err = analyzer.AnalyzeFile(filePath)
+		classes, err = a.AnalyzeFile(filePath)
 
 		// 为单文件分析添加文件路径
 		for _, class := range classes {
@@ -80,44 +129,65 @@ func main() {
 		}
 	}
 
-	if err != nil {
+	printDiagnostics(a.Diagnostics(), opts.Diagnostics)
+	if opts.CacheDir != "" {
+		stats := a.CacheStats()
+		fmt.Printf("缓存: 命中 %d 个文件，重新解析 %d 个文件\n", stats.Hits, stats.Misses)
+	}
+
+	// 部分文件分析失败时仍用其余文件的结果生成报告，最后以非零状态退出
+	failed, fatal := analysisFailure(err)
+	if fatal {
 		fmt.Printf("分析失败: %v\n", err)
 		os.Exit(1)
 	}
 
 	if len(classes) == 0 {
 		fmt.Println("未发现任何类定义")
+		exitIfFailed(failed)
 		return
 	}
 
 	fmt.Printf("发现 %d 个类:\n", len(classes))
 	for _, class := range classes {
-		fmt.Printf("- %s", class.Name)
+		fmt.Printf("- %s", class.QualifiedName)
 		if len(class.BaseClasses) > 0 {
-			fmt.Printf(" (继承自: %v)", class.BaseClasses)
+			var bases []string
+			for _, baseClass := range class.BaseClasses {
+				if baseClass.External {
+					bases = append(bases, baseClass.FullName()+"(外部)")
+				} else {
+					bases = append(bases, baseClass.FullName())
+				}
+			}
+			fmt.Printf(" (继承自: %v)", bases)
 		}
 		if class.FilePath != "" {
 			fmt.Printf(" [文件: %s]", filepath.Base(class.FilePath))
 		}
 		fmt.Println()
-	} // 生成可视化结果
+	}
+
+	// 生成可视化结果
+	classes = applyExternalMode(classes, opts.External, a.Catalog())
+	graph := a.IncludeGraph()
 	switch outputFormat {
 	case "text":
-		err = generateTextReport(classes, "inheritance_report.txt")
+		err = generateTextReport(classes, graph, "inheritance_report.txt")
 		if err != nil {
 			fmt.Printf("生成文本报告失败: %v\n", err)
 		} else {
 			fmt.Println("继承关系报告已生成: inheritance_report.txt")
 		}
 	case "interactive", "interactive-html":
-		err = generateInteractiveHTMLReport(classes, "inheritance_interactive.html")
+		err = generateInteractiveHTMLReport(classes, graph, "inheritance_interactive.html")
 		if err != nil {
 			fmt.Printf("生成交互式HTML报告失败: %v\n", err)
 		} else {
 			fmt.Println("交互式继承关系报告已生成: inheritance_interactive.html")
 		}
 	case "html":
-		err = generateHTMLReport(classes, "inheritance_report.html")
+		err = generateHTMLReport(classes, graph, "inheritance_report.html")
 		if err != nil {
 			fmt.Printf("生成HTML报告失败: %v\n", err)
 		} else {
@@ -125,7 +195,7 @@ func main() {
 		}
 	case "all":
 		// 生成文本报告
-		err = generateTextReport(classes, "inheritance_report.txt")
+		err = generateTextReport(classes, graph, "inheritance_report.txt")
 		if err != nil {
 			fmt.Printf("生成文本报告失败: %v\n", err)
 		} else {
@@ -133,7 +203,7 @@ func main() {
 		}
 
 		// 生成HTML报告
-		err = generateHTMLReport(classes, "inheritance_report.html")
+		err = generateHTMLReport(classes, graph, "inheritance_report.html")
 		if err != nil {
 			fmt.Printf("生成HTML报告失败: %v\n", err)
 		} else {
@@ -141,20 +211,95 @@ func main() {
 		}
 
 		// 生成交互式HTML报告
-		err = generateInteractiveHTMLReport(classes, "inheritance_interactive.html")
+		err = generateInteractiveHTMLReport(classes, graph, "inheritance_interactive.html")
 		if err != nil {
 			fmt.Printf("生成交互式HTML报告失败: %v\n", err)
 		} else {
 			fmt.Println("交互式继承关系报告已生成: inheritance_interactive.html")
 		}
+
+		// 导出包含关系图
+		if hasIncludes(graph) {
+			err = generateIncludeGraph(graph, "include_graph.dot")
+			if err != nil {
+				fmt.Printf("导出包含关系图失败: %v\n", err)
+			} else {
+				fmt.Println("包含关系图已导出: include_graph.dot")
+			}
+		}
+	case "includes":
+		err = generateIncludeGraph(graph, "include_graph.dot")
+		if err != nil {
+			fmt.Printf("导出包含关系图失败: %v\n", err)
+		} else {
+			fmt.Println("包含关系图已导出: include_graph.dot")
+		}
 	default:
 		fmt.Printf("不支持的输出格式: %s\n", outputFormat)
-		fmt.Println("支持的格式: text, html, interactive, all")
+		fmt.Println("支持的格式: text, html, interactive, includes, all")
+	}
+
+	exitIfFailed(failed)
+}
+
+// analysisFailure 区分分析返回的错误: 部分文件分析失败时返回汇总的错误，
+// 无法得到任何结果(如项目目录不存在)时 fatal 为 true
+func analysisFailure(err error) (failed *analyzer.AnalysisError, fatal bool) {
+	if err == nil || errors.As(err, &failed) {
+		return failed, false
+	}
+	return nil, true
+}
+
+// exitIfFailed 有文件分析失败时列出这些文件并以非零状态退出
+func exitIfFailed(failed *analyzer.AnalysisError) {
+	if failed == nil {
+		return
+	}
+	fmt.Printf("分析失败: %v\n", failed)
+	os.Exit(1)
+}
+
+// describeFile 在类所在文件的路径后标注文件种类，如 shape.h (头文件)；种类未知时只返回路径
+func describeFile(class *analyzer.CppClass, path string) string {
+	if class.FileKind == "" {
+		return path
+	}
+	return fmt.Sprintf("%s (%s)", path, class.FileKind.Description())
+}
+
+// stdinName 从标准输入读取的源代码在报告中使用的文件名
+const stdinName = "<stdin>"
+
+// analyzeProject 分析项目目录，路径为源代码压缩包时直接分析压缩包中的文件，不需要解压
+func analyzeProject(a *analyzer.CppAnalyzer, projectPath string) ([]*analyzer.CppClass, error) {
+	if analyzer.IsArchive(projectPath) {
+		return a.AnalyzeArchive(projectPath)
+	}
+	return a.AnalyzeProject(projectPath)
+}
+
+// printDiagnostics 把分析产生的诊断输出到标准错误，format 为 text 时每行一条，为 json 时输出 JSON 数组
+func printDiagnostics(diagnostics analyzer.Diagnostics, format string) {
+	if format == diagnosticsJSON {
+		if diagnostics == nil {
+			diagnostics = analyzer.Diagnostics{}
+		}
+		content, _ := json.MarshalIndent(diagnostics, "", "  ")
+		fmt.Fprintln(os.Stderr, string(content))
+		return
+	}
+	for _, d := range diagnostics {
+		fmt.Fprintln(os.Stderr, d)
+	}
+	if len(diagnostics) > 0 {
+		fmt.Fprintf(os.Stderr, "%d 个错误，%d 个警告\n",
+			diagnostics.Count(analyzer.SeverityError), diagnostics.Count(analyzer.SeverityWarning))
 	}
 }
 
 // generateTextReport 生成文本格式的报告
-func generateTextReport(classes []*analyzer.CppClass, outputPath string) error {
+func generateTextReport(classes []*analyzer.CppClass, graph *analyzer.IncludeGraph, outputPath string) error {
 	file, err := os.Create(outputPath)
 	if err != nil {
 		return err
@@ -169,22 +314,68 @@ func generateTextReport(classes []*analyzer.CppClass, outputPath string) error {
 	// 概述
 	fmt.Fprintf(file, "概述\n")
 	fmt.Fprintf(file, "%s\n", strings.Repeat("-", 20))
-	fmt.Fprintf(file, "总类数: %d\n", len(classes))
+	defined, externals := splitExternalClasses(classes)
+	fmt.Fprintf(file, "总类数: %d\n", len(defined))
 
 	rootClasses := analyzer.FindRootClasses(classes)
-	fmt.Fprintf(file, "根类数: %d\n", len(rootClasses))
-	fmt.Fprintf(file, "派生类数: %d\n\n", len(classes)-len(rootClasses))
+	rootCount, derivedCount := countRootClasses(classes, rootClasses)
+	fmt.Fprintf(file, "根类数: %d\n", rootCount)
+	fmt.Fprintf(file, "派生类数: %d\n", derivedCount)
+	if len(externals) > 0 {
+		fmt.Fprintf(file, "外部类数: %d\n", len(externals))
+	}
+	fmt.Fprintf(file, "\n")
+
+	// 接口与实现类
+	fmt.Fprintf(file, "接口与实现类\n")
+	fmt.Fprintf(file, "%s\n", strings.Repeat("-", 20))
+	interfaces := findInterfaces(classes)
+	if len(interfaces) == 0 {
+		fmt.Fprintf(file, "未发现纯接口\n")
+	}
+	for _, iface := range interfaces {
+		fmt.Fprintf(file, "%s <- %s\n", iface.QualifiedName, describeImplementers(classes, iface))
+	}
+	fmt.Fprintf(file, "\n")
 
 	// 类详情
 	fmt.Fprintf(file, "类详情\n")
 	fmt.Fprintf(file, "%s\n", strings.Repeat("-", 20))
 
-	for i, class := range classes {
-		fmt.Fprintf(file, "%d. 类名: %s\n", i+1, class.Name)
+	for i, class := range defined {
+		fmt.Fprintf(file, "%d. 类名: %s\n", i+1, class.QualifiedName)
+		if class.Namespace != "" {
+			fmt.Fprintf(file, "   命名空间: %s\n", class.Namespace)
+		}
 		fmt.Fprintf(file, "   行号: %d\n", class.LineNumber)
+		if class.FilePath != "" {
+			fmt.Fprintf(file, "   文件: %s\n", describeFile(class, class.FilePath))
+		}
+		fmt.Fprintf(file, "   种类: %s (%s)\n", class.Kind.Description(), class.ClassKey)
+		if class.Kind == analyzer.ClassAbstract {
+			fmt.Fprintf(file, "   未实现的纯虚函数: %s\n", strings.Join(class.PureVirtuals, ", "))
+		}
+		if header := class.TemplateHeader(); header != "" {
+			fmt.Fprintf(file, "   模板: %s\n", header)
+		}
+		if class.PrimaryTemplate != "" {
+			fmt.Fprintf(file, "   特化自: %s\n", class.PrimaryTemplate)
+		}
+		if class.EnclosingClass != "" {
+			fmt.Fprintf(file, "   外围类: %s\n", class.EnclosingClass)
+		}
+		if class.EnclosingFunction != "" {
+			fmt.Fprintf(file, "   所在函数: %s\n", class.EnclosingFunction)
+		}
+		if nested := nestedClassNames(classes, class); len(nested) > 0 {
+			fmt.Fprintf(file, "   嵌套类: %s\n", strings.Join(nested, ", "))
+		}
+		if conflicts := conflictingDefinitions(classes, class); len(conflicts) > 0 {
+			fmt.Fprintf(file, "   定义冲突: 另有不同的定义位于 %s\n", strings.Join(conflicts, ", "))
+		}
 
 		if len(class.BaseClasses) > 0 {
-			fmt.Fprintf(file, "   继承自: %s\n", strings.Join(class.BaseClasses, ", "))
+			fmt.Fprintf(file, "   继承自: %s\n", strings.Join(describeBaseClasses(class), ", "))
 		} else {
 			fmt.Fprintf(file, "   根类 (无继承)\n")
 		}
@@ -192,14 +383,28 @@ func generateTextReport(classes []*analyzer.CppClass, outputPath string) error {
 		if len(class.Members) > 0 {
 			fmt.Fprintf(file, "   成员变量 (%d):\n", len(class.Members))
 			for _, member := range class.Members {
-				fmt.Fprintf(file, "     - %s\n", member)
+				fmt.Fprintf(file, "     - [%s] %s\n", member.Access, member)
 			}
 		}
 
 		if len(class.Methods) > 0 {
 			fmt.Fprintf(file, "   成员方法 (%d):\n", len(class.Methods))
 			for _, method := range class.Methods {
-				fmt.Fprintf(file, "     - %s\n", method)
+				fmt.Fprintf(file, "     - [%s] %s", method.Access, method)
+				for _, tag := range methodTags(method) {
+					fmt.Fprintf(file, " [%s]", tag)
+				}
+				if location := definitionLocation(class, method); location != "" {
+					fmt.Fprintf(file, " [定义于 %s]", location)
+				}
+				fmt.Fprintf(file, "\n")
+			}
+		}
+
+		if len(class.UndeclaredMethods) > 0 {
+			fmt.Fprintf(file, "   未声明的类体外定义 (%d):\n", len(class.UndeclaredMethods))
+			for _, method := range class.UndeclaredMethods {
+				fmt.Fprintf(file, "     - %s [定义于 %s]\n", method, definitionLocation(class, method))
 			}
 		}
 
@@ -212,31 +417,63 @@ func generateTextReport(classes []*analyzer.CppClass, outputPath string) error {
 
 	tree := analyzer.GetInheritanceTree(classes)
 	for _, rootClass := range rootClasses {
-		printClassHierarchyText(file, rootClass, tree, 0)
+		printClassHierarchyText(file, rootClass, nil, tree, 0, make(map[*analyzer.CppClass]bool))
+	}
+
+	// 包含关系
+	if hasIncludes(graph) {
+		fmt.Fprintf(file, "\n包含关系\n")
+		fmt.Fprintf(file, "%s\n", strings.Repeat("-", 20))
+		for _, path := range graph.Files {
+			fmt.Fprintf(file, "%s\n", path)
+			for _, include := range describeIncludes(graph, path) {
+				fmt.Fprintf(file, "  -> %s\n", include)
+			}
+		}
 	}
 
 	return nil
 }
 
-// printClassHierarchyText 递归打印类层次结构到文本文件
-func printClassHierarchyText(file *os.File, class *analyzer.CppClass, tree map[string][]*analyzer.CppClass, level int) {
+// printClassHierarchyText 递归打印类层次结构到文本文件，parent 为上一层的基类
+// ancestors 为当前路径上的类；同名类的冲突定义可能形成循环继承，再次遇到路径上的类时标注后停止
+func printClassHierarchyText(file *os.File, class, parent *analyzer.CppClass, tree map[string][]*analyzer.CppClass, level int, ancestors map[*analyzer.CppClass]bool) {
 	indent := strings.Repeat("  ", level)
 	symbol := "+"
 	if level > 0 {
 		symbol = "├─"
 	}
 
-	fmt.Fprintf(file, "%s%s %s\n", indent, symbol, class.Name)
+	fmt.Fprintf(file, "%s%s %s", indent, symbol, class.QualifiedName)
+	if class.External {
+		fmt.Fprintf(file, " %s", externalLabel(class))
+	}
+	if edge := inheritanceEdgeLabel(class, parent); edge != "" {
+		fmt.Fprintf(file, " [%s]", edge)
+	}
+	if external := otherExternalBases(class, parent); len(external) > 0 {
+		fmt.Fprintf(file, " (外部基类: %s)", strings.Join(external, ", "))
+	}
+	if enclosedBy := enclosingName(class); enclosedBy != "" {
+		fmt.Fprintf(file, " (嵌套于 %s)", enclosedBy)
+	}
+	if ancestors[class] {
+		fmt.Fprintf(file, " %s\n", cycleMarker)
+		return
+	}
+	fmt.Fprintf(file, "\n")
 
-	if children, exists := tree[class.Name]; exists {
+	ancestors[class] = true
+	defer delete(ancestors, class)
+	if children, exists := tree[class.QualifiedName]; exists {
 		for _, child := range children {
-			printClassHierarchyText(file, child, tree, level+1)
+			printClassHierarchyText(file, child, class, tree, level+1, ancestors)
 		}
 	}
 }
 
 // generateHTMLReport 生成HTML格式的报告
-func generateHTMLReport(classes []*analyzer.CppClass, outputPath string) error {
+func generateHTMLReport(classes []*analyzer.CppClass, graph *analyzer.IncludeGraph, outputPath string) error {
 	file, err := os.Create(outputPath)
 	if err != nil {
 		return err
@@ -309,6 +546,10 @@ func generateHTMLReport(classes []*analyzer.CppClass, outputPath string) error {
         .methods li::before {
             content: "⚙️ ";
         }
+        .access {
+            color: #888;
+            font-size: 0.85em;
+        }
         .hierarchy {
             background-color: #f0f0f0;
             padding: 15px;
@@ -323,6 +564,33 @@ func generateHTMLReport(classes []*analyzer.CppClass, outputPath string) error {
         .derived-class {
             color: #2196F3;
         }
+        .external-class {
+            color: #999;
+            font-style: italic;
+            border-bottom: 1px dotted #999;
+        }
+        .class-card.abstract {
+            border: 1px dashed #FF9800;
+        }
+        .class-card.interface {
+            border: 3px double #9C27B0;
+        }
+        .interfaces {
+            background-color: #f3e5f5;
+            padding: 10px 15px;
+            border-radius: 5px;
+        }
+        .interface-name {
+            color: #9C27B0;
+            font-weight: bold;
+        }
+        .edge {
+            color: #888;
+        }
+        .virtual-edge {
+            border-bottom: 1px dashed #FF9800;
+            color: #FF9800;
+        }
         .stats {
             display: flex;
             justify-content: space-around;
@@ -340,6 +608,19 @@ func generateHTMLReport(classes []*analyzer.CppClass, outputPath string) error {
             font-weight: bold;
             color: #1976D2;
         }
+        .includes {
+            background-color: #fff8e1;
+            padding: 10px 15px;
+            border-radius: 5px;
+            font-family: monospace;
+        }
+        .includes ul {
+            margin: 0 0 10px 0;
+        }
+        .missing-include {
+            color: #999;
+            font-style: italic;
+        }
     </style>
 </head>
 <body>
@@ -349,7 +630,9 @@ func generateHTMLReport(classes []*analyzer.CppClass, outputPath string) error {
 `, time.Now().Format("2006-01-02 15:04:05"))
 
 	// 统计信息
+	defined, externals := splitExternalClasses(classes)
 	rootClasses := analyzer.FindRootClasses(classes)
+	rootCount, derivedCount := countRootClasses(classes, rootClasses)
 	fmt.Fprintf(file, `
         <div class="overview">
             <h2>📊 统计概览</h2>
@@ -366,24 +649,87 @@ func generateHTMLReport(classes []*analyzer.CppClass, outputPath string) error {
                     <div class="stat-number">%d</div>
                     <div>派生类数</div>
                 </div>
-            </div>
+`, len(defined), rootCount, derivedCount)
+	if len(externals) > 0 {
+		fmt.Fprintf(file, `                <div class="stat-item">
+                    <div class="stat-number">%d</div>
+                    <div>外部类数</div>
+                </div>
+`, len(externals))
+	}
+	fmt.Fprintf(file, `            </div>
         </div>
-`, len(classes), len(rootClasses), len(classes)-len(rootClasses))
+`)
+
+	// 接口与实现类
+	fmt.Fprintf(file, `
+        <h2>🧩 接口与实现类</h2>
+        <div class="interfaces">
+`)
+	interfaces := findInterfaces(classes)
+	if len(interfaces) == 0 {
+		fmt.Fprintf(file, `            <p><em>未发现纯接口</em></p>
+`)
+	}
+	for _, iface := range interfaces {
+		fmt.Fprintf(file, `            <p><span class="interface-name">%s</span> ← %s</p>
+`, htmlEscape(iface.QualifiedName), htmlEscape(describeImplementers(classes, iface)))
+	}
+	fmt.Fprintf(file, `        </div>
+`)
 
 	// 类详情
 	fmt.Fprintf(file, `
         <h2>📚 类详情</h2>
 `)
 
-	for i, class := range classes {
+	for i, class := range defined {
 		fmt.Fprintf(file, `
-        <div class="class-card">
+        <div class="class-card %s">
             <div class="class-name">%d. %s</div>
             <p><strong>定义位置:</strong> 第 %d 行</p>
-`, i+1, htmlEscape(class.Name), class.LineNumber)
+            <p><strong>种类:</strong> %s (<code>%s</code>)</p>
+`, class.Kind, i+1, htmlEscape(class.QualifiedName), class.LineNumber, class.Kind.Description(), class.ClassKey)
+		if class.FilePath != "" {
+			fmt.Fprintf(file, `            <p><strong>文件:</strong> %s</p>
+`, htmlEscape(describeFile(class, class.FilePath)))
+		}
+		if class.Kind == analyzer.ClassAbstract {
+			fmt.Fprintf(file, `            <p><strong>未实现的纯虚函数:</strong> %s</p>
+`, htmlEscape(strings.Join(class.PureVirtuals, ", ")))
+		}
+
+		if class.Namespace != "" {
+			fmt.Fprintf(file, `            <p><strong>命名空间:</strong> %s</p>
+`, htmlEscape(class.Namespace))
+		}
+		if header := class.TemplateHeader(); header != "" {
+			fmt.Fprintf(file, `            <p><strong>模板:</strong> <code>%s</code></p>
+`, htmlEscape(header))
+		}
+		if class.PrimaryTemplate != "" {
+			fmt.Fprintf(file, `            <p><strong>特化自:</strong> %s</p>
+`, htmlEscape(class.PrimaryTemplate))
+		}
+		if class.EnclosingClass != "" {
+			fmt.Fprintf(file, `            <p><strong>外围类:</strong> %s</p>
+`, htmlEscape(class.EnclosingClass))
+		}
+		if class.EnclosingFunction != "" {
+			fmt.Fprintf(file, `            <p><strong>所在函数:</strong> %s</p>
+`, htmlEscape(class.EnclosingFunction))
+		}
+		if nested := nestedClassNames(classes, class); len(nested) > 0 {
+			fmt.Fprintf(file, `            <p><strong>嵌套类:</strong> %s</p>
+`, htmlEscape(strings.Join(nested, ", ")))
+		}
+		if conflicts := conflictingDefinitions(classes, class); len(conflicts) > 0 {
+			fmt.Fprintf(file, `            <p><strong>⚠️ 定义冲突:</strong> 另有不同的定义位于 %s</p>
+`, htmlEscape(strings.Join(conflicts, ", ")))
+		}
 
 		if len(class.BaseClasses) > 0 {
-			fmt.Fprintf(file, `            <p class="inheritance">🔗 继承自: %s</p>`, htmlEscape(strings.Join(class.BaseClasses, ", ")))
+			fmt.Fprintf(file, `            <p class="inheritance">🔗 继承自: %s</p>`, htmlEscape(strings.Join(describeBaseClasses(class), ", ")))
 		} else {
 			fmt.Fprintf(file, `            <p><em>🌳 根类 (无继承关系)</em></p>`)
 		}
@@ -395,7 +741,7 @@ func generateHTMLReport(classes []*analyzer.CppClass, outputPath string) error {
                 <ul>
 `, len(class.Members))
 			for _, member := range class.Members {
-				fmt.Fprintf(file, `                    <li>%s</li>`, htmlEscape(member))
+				fmt.Fprintf(file, `                    <li><span class="access">%s</span> %s</li>`, member.Access, htmlEscape(member.String()))
 			}
 			fmt.Fprintf(file, `                </ul>
             </div>`)
@@ -408,7 +754,27 @@ func generateHTMLReport(classes []*analyzer.CppClass, outputPath string) error {
                 <ul>
 `, len(class.Methods))
 			for _, method := range class.Methods {
-				fmt.Fprintf(file, `                    <li>%s</li>`, htmlEscape(method))
+				desc := method.String()
+				for _, tag := range methodTags(method) {
+					desc += " [" + tag + "]"
+				}
+				if location := definitionLocation(class, method); location != "" {
+					desc += " [定义于 " + location + "]"
+				}
+				fmt.Fprintf(file, `                    <li><span class="access">%s</span> %s</li>`, method.Access, htmlEscape(desc))
+			}
+			fmt.Fprintf(file, `                </ul>
+            </div>`)
+		}
+
+		if len(class.UndeclaredMethods) > 0 {
+			fmt.Fprintf(file, `
+            <div class="methods">
+                <strong>未声明的类体外定义 (%d个):</strong>
+                <ul>
+`, len(class.UndeclaredMethods))
+			for _, method := range class.UndeclaredMethods {
+				fmt.Fprintf(file, `                    <li>%s</li>`, htmlEscape(method.String()+" [定义于 "+definitionLocation(class, method)+"]"))
 			}
 			fmt.Fprintf(file, `                </ul>
             </div>`)
@@ -424,12 +790,40 @@ func generateHTMLReport(classes []*analyzer.CppClass, outputPath string) error {
 
 	tree := analyzer.GetInheritanceTree(classes)
 	for _, rootClass := range rootClasses {
-		hierarchyHTML := buildClassHierarchyHTML(rootClass, tree, 0)
+		hierarchyHTML := buildClassHierarchyHTML(rootClass, nil, tree, 0, make(map[*analyzer.CppClass]bool))
 		fmt.Fprintf(file, "%s", hierarchyHTML)
 	}
 
 	fmt.Fprintf(file, `        </div>
-    </div>
+`)
+
+	// 包含关系
+	if hasIncludes(graph) {
+		fmt.Fprintf(file, `
+        <h2>📂 包含关系</h2>
+        <div class="includes">
+`)
+		for _, path := range graph.Files {
+			fmt.Fprintf(file, `            <div>%s</div>
+            <ul>
+`, htmlEscape(path))
+			for _, include := range graph.Includes[path] {
+				if include.Resolved == "" {
+					fmt.Fprintf(file, `                <li class="missing-include">%s (未找到)</li>
+`, htmlEscape(include.String()))
+				} else {
+					fmt.Fprintf(file, `                <li>%s → %s</li>
+`, htmlEscape(include.String()), htmlEscape(include.Resolved))
+				}
+			}
+			fmt.Fprintf(file, `            </ul>
+`)
+		}
+		fmt.Fprintf(file, `        </div>
+`)
+	}
+
+	fmt.Fprintf(file, `    </div>
 </body>
 </html>`)
 
@@ -446,8 +840,9 @@ func htmlEscape(s string) string {
 	return s
 }
 
-// buildClassHierarchyHTML 构建HTML格式的类层次结构
-func buildClassHierarchyHTML(class *analyzer.CppClass, tree map[string][]*analyzer.CppClass, level int) string {
+// buildClassHierarchyHTML 构建HTML格式的类层次结构，parent 为上一层的基类
+// ancestors 为当前路径上的类，用法与 printClassHierarchyText 相同
+func buildClassHierarchyHTML(class, parent *analyzer.CppClass, tree map[string][]*analyzer.CppClass, level int, ancestors map[*analyzer.CppClass]bool) string {
 	var result strings.Builder
 
 	indent := strings.Repeat("  ", level)
@@ -456,37 +851,151 @@ func buildClassHierarchyHTML(class *analyzer.CppClass, tree map[string][]*analyz
 		symbol = "├─"
 	}
 
-	className := htmlEscape(class.Name)
-	if level == 0 {
+	className := htmlEscape(class.QualifiedName)
+	if class.External {
+		className = fmt.Sprintf(`<span class="external-class">%s</span> %s`, className, htmlEscape(externalLabel(class)))
+	} else if level == 0 {
 		className = fmt.Sprintf(`<span class="root-class">%s</span>`, className)
 	} else {
 		className = fmt.Sprintf(`<span class="derived-class">%s</span>`, className)
 	}
 
+	if enclosedBy := enclosingName(class); enclosedBy != "" {
+		className += fmt.Sprintf(` <span class="edge">(嵌套于 %s)</span>`, htmlEscape(enclosedBy))
+	}
+	if edge := inheritanceEdgeLabel(class, parent); edge != "" {
+		edgeClass := "edge"
+		if base, _ := findBaseSpecifier(class, parent); base.Virtual {
+			edgeClass = "edge virtual-edge"
+		}
+		className += fmt.Sprintf(` <span class="%s">[%s]</span>`, edgeClass, htmlEscape(edge))
+	}
+	if external := otherExternalBases(class, parent); len(external) > 0 {
+		className += fmt.Sprintf(` <span class="external-class">(外部基类: %s)</span>`, htmlEscape(strings.Join(external, ", ")))
+	}
+
+	if ancestors[class] {
+		result.WriteString(fmt.Sprintf("%s%s %s %s\n", indent, symbol, className, htmlEscape(cycleMarker)))
+		return result.String()
+	}
 	result.WriteString(fmt.Sprintf("%s%s %s\n", indent, symbol, className))
 
-	if children, exists := tree[class.Name]; exists {
+	ancestors[class] = true
+	defer delete(ancestors, class)
+	if children, exists := tree[class.QualifiedName]; exists {
 		for _, child := range children {
-			result.WriteString(buildClassHierarchyHTML(child, tree, level+1))
+			result.WriteString(buildClassHierarchyHTML(child, class, tree, level+1, ancestors))
 		}
 	}
 	return result.String()
 }
 
+// cycleMarker 层次结构中再次遇到当前路径上的类时的标注
+const cycleMarker = "(循环继承，见上层)"
+
 // generateInteractiveHTMLReport 生成交互式HTML格式的报告
-func generateInteractiveHTMLReport(classes []*analyzer.CppClass, outputPath string) error {
+func generateInteractiveHTMLReport(classes []*analyzer.CppClass, graph *analyzer.IncludeGraph, outputPath string) error {
 	htmlGen := visualizer.NewHTMLGenerator()
+	if hasIncludes(graph) {
+		for _, path := range graph.Files {
+			includeFile := &visualizer.HTMLIncludeFile{File: path}
+			for _, include := range graph.Includes[path] {
+				includeFile.Includes = append(includeFile.Includes, visualizer.HTMLInclude{
+					Name:   include.String(),
+					Target: include.Resolved,
+				})
+			}
+			htmlGen.AddIncludeFile(includeFile)
+		}
+	}
+
+	known := analyzer.ClassKeys(classes)
 
 	// 添加所有类到HTML生成器
 	for _, class := range classes {
+		var parents []visualizer.HTMLParent
+		for _, baseClass := range class.BaseClasses {
+			label := baseClass.FullName()
+			if baseClass.Variadic {
+				label += "..."
+			}
+			parents = append(parents, visualizer.HTMLParent{
+				Name:     analyzer.BaseKey(baseClass, known),
+				Label:    label,
+				Access:   baseClass.Access,
+				Virtual:  baseClass.Virtual,
+				Tags:     baseClassTags(class, baseClass),
+				External: baseClass.External,
+			})
+		}
+
+		if class.External {
+			htmlGen.AddClass(&visualizer.HTMLClass{
+				Name:     class.QualifiedName,
+				Parents:  parents,
+				External: true,
+				Catalog:  class.Catalog,
+			})
+			continue
+		}
+
 		filePath := class.FilePath
 		if filePath == "" {
 			filePath = "未知文件"
 		} else {
-			filePath = filepath.Base(filePath)
+			filePath = describeFile(class, filepath.Base(filePath))
+		}
+
+		var members []visualizer.HTMLMember
+		for _, member := range class.Members {
+			members = append(members, visualizer.HTMLMember{
+				Access:      member.Access,
+				Declaration: member.String(),
+			})
 		}
 
-		htmlGen.AddClass(class.Name, class.Members, class.Methods, class.BaseClasses, filePath)
+		var methods []visualizer.HTMLMember
+		for _, method := range class.Methods {
+			methods = append(methods, visualizer.HTMLMember{
+				Access:      method.Access,
+				Declaration: method.String(),
+				Tags:        methodTags(method),
+				Location:    definitionLocation(class, method),
+			})
+		}
+
+		var undeclared []visualizer.HTMLMember
+		for _, method := range class.UndeclaredMethods {
+			undeclared = append(undeclared, visualizer.HTMLMember{
+				Declaration: method.String(),
+				Location:    definitionLocation(class, method),
+			})
+		}
+
+		var implementers []string
+		if class.Kind == analyzer.ClassInterface {
+			for _, impl := range analyzer.FindImplementers(classes, class) {
+				implementers = append(implementers, impl.QualifiedName)
+			}
+		}
+
+		htmlGen.AddClass(&visualizer.HTMLClass{
+			Name:     class.QualifiedName,
+			Template: class.TemplateHeader(),
+			Primary:  class.PrimaryTemplate,
+			Members:  members,
+			Methods:  methods,
+			Parents:  parents,
+			Kind:     string(class.Kind),
+			ClassKey: class.ClassKey,
+			FilePath: filePath,
+
+			EnclosedBy:   enclosingName(class),
+			Nested:       nestedClassNames(classes, class),
+			Implementers: implementers,
+			Undeclared:   undeclared,
+			Conflicts:    conflictingDefinitions(classes, class),
+		})
 	}
 
 	// 生成HTML内容
@@ -502,6 +1011,251 @@ func generateInteractiveHTMLReport(classes []*analyzer.CppClass, outputPath stri
 	return err
 }
 
+// baseClassTags 返回基类的标记，如 CRTP 和形参包展开
+func baseClassTags(class *analyzer.CppClass, baseClass analyzer.BaseSpecifier) []string {
+	var tags []string
+	if class.IsCRTPBase(baseClass) {
+		tags = append(tags, "CRTP")
+	}
+	if baseClass.Variadic {
+		tags = append(tags, "变参展开")
+	}
+	if baseClass.External {
+		tags = append(tags, "外部")
+	}
+	return tags
+}
+
+// applyExternalMode 按 -external 选项处理报告中的外部基类
+// show 时追加外部类节点，目录中有记录的外部类一直展开到继承链的根；hide 时从基类列表中移除外部基类；collapse 时保持原样，只在派生类上标注
+func applyExternalMode(classes []*analyzer.CppClass, mode string, catalog *analyzer.Catalog) []*analyzer.CppClass {
+	switch mode {
+	case externalShow:
+		return append(classes, analyzer.ExternalClasses(classes, catalog)...)
+	case externalHide:
+		for _, class := range classes {
+			var bases []analyzer.BaseSpecifier
+			for _, baseClass := range class.BaseClasses {
+				if !baseClass.External {
+					bases = append(bases, baseClass)
+				}
+			}
+			class.BaseClasses = bases
+		}
+	}
+	return classes
+}
+
+// externalLabel 返回外部类节点的标注，如 «外部类: std»
+func externalLabel(class *analyzer.CppClass) string {
+	if class.Catalog != "" {
+		return "«外部类: " + class.Catalog + "»"
+	}
+	return "«外部类»"
+}
+
+// splitExternalClasses 把类列表分为分析得到的类和外部类节点
+func splitExternalClasses(classes []*analyzer.CppClass) (defined, externals []*analyzer.CppClass) {
+	for _, class := range classes {
+		if class.External {
+			externals = append(externals, class)
+		} else {
+			defined = append(defined, class)
+		}
+	}
+	return defined, externals
+}
+
+// countRootClasses 统计分析得到的类中根类和派生类的数量，roots 为 FindRootClasses 的结果
+// 外部类节点不参与统计，它们可能有基类，如 std::invalid_argument 继承自 std::logic_error
+func countRootClasses(classes, roots []*analyzer.CppClass) (rootCount, derivedCount int) {
+	isRoot := make(map[*analyzer.CppClass]bool, len(roots))
+	for _, root := range roots {
+		isRoot[root] = true
+	}
+	for _, class := range classes {
+		switch {
+		case class.External:
+		case isRoot[class]:
+			rootCount++
+		default:
+			derivedCount++
+		}
+	}
+	return rootCount, derivedCount
+}
+
+// otherExternalBases 返回层次结构中需要在类旁标注的外部基类名，即除上一层的外部类节点之外的外部基类
+// 外部类节点的基类总是作为节点展示，不需要标注
+func otherExternalBases(class, parent *analyzer.CppClass) []string {
+	if class.External {
+		return nil
+	}
+	var names []string
+	for _, baseClass := range class.BaseClasses {
+		if baseClass.External && (parent == nil || baseClass.Name != parent.QualifiedName) {
+			names = append(names, baseClass.FullName())
+		}
+	}
+	return names
+}
+
+// describeBaseClasses 返回带继承方式和标记的基类描述列表
+func describeBaseClasses(class *analyzer.CppClass) []string {
+	var bases []string
+	for _, baseClass := range class.BaseClasses {
+		desc := baseClass.String()
+		for _, tag := range baseClassTags(class, baseClass) {
+			desc += " [" + tag + "]"
+		}
+		bases = append(bases, desc)
+	}
+	return bases
+}
+
+// findInterfaces 返回所有纯接口
+func findInterfaces(classes []*analyzer.CppClass) []*analyzer.CppClass {
+	var interfaces []*analyzer.CppClass
+	for _, class := range classes {
+		if class.Kind == analyzer.ClassInterface {
+			interfaces = append(interfaces, class)
+		}
+	}
+	return interfaces
+}
+
+// describeImplementers 返回接口所有实现类的名字列表
+func describeImplementers(classes []*analyzer.CppClass, iface *analyzer.CppClass) string {
+	var names []string
+	for _, impl := range analyzer.FindImplementers(classes, iface) {
+		names = append(names, impl.QualifiedName)
+	}
+	if len(names) == 0 {
+		return "(暂无实现类)"
+	}
+	return strings.Join(names, ", ")
+}
+
+// enclosingName 返回嵌套类的外围类或局部类所在的函数，普通类为空
+func enclosingName(class *analyzer.CppClass) string {
+	if class.EnclosingClass != "" {
+		return class.EnclosingClass
+	}
+	if class.EnclosingFunction != "" {
+		return class.EnclosingFunction + "()"
+	}
+	return ""
+}
+
+// nestedClassNames 返回直接嵌套在类中的类名
+func nestedClassNames(classes []*analyzer.CppClass, class *analyzer.CppClass) []string {
+	var names []string
+	for _, nested := range analyzer.FindNestedClasses(classes, class) {
+		names = append(names, nested.QualifiedName)
+	}
+	return names
+}
+
+// conflictingDefinitions 返回同名类其他不同定义的位置，如 include/config.h:3
+func conflictingDefinitions(classes []*analyzer.CppClass, class *analyzer.CppClass) []string {
+	if !class.Conflicting {
+		return nil
+	}
+	var locations []string
+	for _, other := range classes {
+		if other != class && other.Conflicting && other.QualifiedName == class.QualifiedName {
+			locations = append(locations, fmt.Sprintf("%s:%d", other.FilePath, other.LineNumber))
+		}
+	}
+	return locations
+}
+
+// methodTags 返回成员方法的标记，如构造函数、纯虚函数
+func methodTags(method analyzer.Method) []string {
+	var tags []string
+	switch method.Kind {
+	case analyzer.MethodConstructor:
+		tags = append(tags, "构造函数")
+	case analyzer.MethodDestructor:
+		tags = append(tags, "析构函数")
+	case analyzer.MethodOperator:
+		tags = append(tags, "运算符重载")
+	case analyzer.MethodConversion:
+		tags = append(tags, "类型转换")
+	}
+	if method.Pure {
+		tags = append(tags, "纯虚函数")
+	}
+	if method.Undefined {
+		tags = append(tags, "未定义")
+	}
+	return tags
+}
+
+// definitionLocation 返回方法在类体外的定义位置，如 circle.cpp:12；在类体内定义或没有找到定义时为空
+func definitionLocation(class *analyzer.CppClass, method analyzer.Method) string {
+	if method.DefinitionLine == 0 || (method.DefinitionFile == class.FilePath && method.DefinitionLine == method.LineNumber) {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", filepath.Base(method.DefinitionFile), method.DefinitionLine)
+}
+
+// findBaseSpecifier 返回child继承parent时使用的基类说明符
+func findBaseSpecifier(child, parent *analyzer.CppClass) (analyzer.BaseSpecifier, bool) {
+	if parent == nil {
+		return analyzer.BaseSpecifier{}, false
+	}
+	for _, baseClass := range child.BaseClasses {
+		if baseClass.Name == parent.QualifiedName || baseClass.FullName() == parent.QualifiedName {
+			return baseClass, true
+		}
+	}
+	return analyzer.BaseSpecifier{}, false
+}
+
+// inheritanceEdgeLabel 返回层次结构中继承边的说明，仅虚继承或非 public 继承时非空
+func inheritanceEdgeLabel(child, parent *analyzer.CppClass) string {
+	baseClass, found := findBaseSpecifier(child, parent)
+	if !found || (!baseClass.Virtual && baseClass.Access == "public") {
+		return ""
+	}
+	if baseClass.Virtual {
+		return "virtual " + baseClass.Access
+	}
+	return baseClass.Access
+}
+
+// hasIncludes 判断包含关系图中是否记录了 #include 指令
+func hasIncludes(graph *analyzer.IncludeGraph) bool {
+	if graph == nil {
+		return false
+	}
+	for _, includes := range graph.Includes {
+		if len(includes) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// describeIncludes 返回文件中各条 #include 指令的描述，如 "shape.h" => test_project/shape.h
+func describeIncludes(graph *analyzer.IncludeGraph, path string) []string {
+	var result []string
+	for _, include := range graph.Includes[path] {
+		if include.Resolved == "" {
+			result = append(result, include.String()+" (未找到)")
+		} else {
+			result = append(result, include.String()+" => "+include.Resolved)
+		}
+	}
+	return result
+}
+
+// generateIncludeGraph 以 Graphviz DOT 格式导出包含关系图
+func generateIncludeGraph(graph *analyzer.IncludeGraph, outputPath string) error {
+	return os.WriteFile(outputPath, []byte(graph.DOT()), 0644)
+}
+
 // showHelp 显示命令行帮助信息
 func showHelp() {
 	fmt.Println("C++ 类继承关系分析器")
@@ -509,16 +1263,38 @@ func showHelp() {
 	fmt.Println()
 	fmt.Println("用法:")
 	fmt.Println("  go run main.go [选项] <文件/目录> [输出格式]")
+	fmt.Println("  go run main.go [选项] <源代码压缩包.zip|.tar.gz|.tgz|.tar> [输出格式]")
+	fmt.Println("  go run main.go [选项] - [输出格式]    从标准输入读取源代码，- 只能单独使用")
 	fmt.Println()
 	fmt.Println("选项:")
 	fmt.Println("  -h, --help     显示此帮助信息")
-	fmt.Println("  -project <dir> 分析指定项目目录")
+	fmt.Println("  -project <dir> 分析指定项目目录，<dir> 也可以是源代码压缩包")
 	fmt.Println("  -files <f1> <f2> ... 分析多个指定文件")
+	fmt.Println("  -compile-commands <path> 按 compile_commands.json 分析其中列出的翻译单元")
+	fmt.Println("  -D <name>[=<value>] 定义预处理宏，-D NAME= 定义为空宏，-D \"F(x)=...\" 定义函数式宏")
+	fmt.Println("  -U <name>      取消预处理宏的定义")
+	fmt.Println("  -I <dir>       添加头文件搜索路径，用于解析 #include")
+	fmt.Println("  -external <mode> 未找到定义的外部基类(如 std::runtime_error)的展示方式:")
+	fmt.Println("                 show 作为外部类节点展示(默认)，collapse 只在派生类上标注，hide 隐藏")
+	fmt.Println("  -catalog <name|file> 加载类层次目录: 内置的 qt、boost (std 默认加载)，或自定义的目录文件")
+	fmt.Println("  --keep-going   有文件分析失败时继续分析其余文件并生成报告，最后以非零状态退出(默认)")
+	fmt.Println("  --fail-fast    遇到第一个分析失败的文件时停止")
+	fmt.Println("  -j <n>         同时解析的文件数，默认为 CPU 核数；结果的顺序与并发数无关")
+	fmt.Println("  --cache        在 .easycpp-cache 目录中缓存各文件的解析结果，只重新解析有变化的文件")
+	fmt.Println("  -cache-dir <dir> 使用指定的缓存目录；--no-cache 不使用缓存(默认)")
+	fmt.Println("  --exclude <glob> 分析项目时排除匹配的文件和目录(可重复)，如 build、third_party/、**/*.pb.h")
+	fmt.Println("                 被排除的文件中的类被引用为基类时作为外部基类")
+	fmt.Println("  --include <glob> 分析项目时只从匹配的文件开始分析(可重复)，如 src/**")
+	fmt.Println("  --gitignore    分析项目时跳过 .gitignore 忽略的文件和目录")
+	fmt.Println("  -ext <.ext>=<kind> 把扩展名识别为 header、source 或 module 文件(可重复)，kind 为 none 时不再识别")
+	fmt.Println("  -diagnostics <format> 诊断(警告、错误)输出到标准错误的格式:")
+	fmt.Println("                 text 编译器风格的 文件:行:列: warning: ... (默认)，json 为 JSON 数组")
 	fmt.Println()
 	fmt.Println("输出格式:")
 	fmt.Println("  text         纯文本报告 (inheritance_report.txt)")
 	fmt.Println("  html         静态HTML报告 (inheritance_report.html)")
 	fmt.Println("  interactive  交互式HTML报告 (inheritance_interactive.html)")
+	fmt.Println("  includes     包含关系图 (include_graph.dot)")
 	fmt.Println("  all          生成所有格式 (默认)")
 	fmt.Println()
 	fmt.Println("示例:")
@@ -527,14 +1303,24 @@ func showHelp() {
 	fmt.Println("  go run main.go -project ./test_project")
 	fmt.Println("  go run main.go -project ./test_project interactive")
 	fmt.Println("  go run main.go -files file1.cpp file2.h")
+	fmt.Println("  go run main.go -DUSE_NEW_API -D VERSION=3 -project ./test_project")
+	fmt.Println("  go run main.go -I ./include -files src/main.cpp includes")
+	fmt.Println("  go run main.go -compile-commands ./build html")
+	fmt.Println("  go run main.go -external collapse -project ./test_project")
+	fmt.Println("  go run main.go -catalog qt -catalog sdk_classes.txt -project ./src")
+	fmt.Println("  go run main.go -diagnostics json -project ./src 2> diagnostics.json")
 	fmt.Println()
 	fmt.Println("支持的C++特性:")
-	fmt.Println("  ✓ 类定义和继承关系")
+	fmt.Println("  ✓ 类定义和继承关系 (class, struct, union)")
 	fmt.Println("  ✓ 单继承和多重继承")
 	fmt.Println("  ✓ 虚函数和纯虚函数")
 	fmt.Println("  ✓ 成员变量和方法")
 	fmt.Println("  ✓ 访问修饰符 (public, private, protected)")
 	fmt.Println("  ✓ 多文件项目分析")
+	fmt.Println("  ✓ 条件编译 (#if, #ifdef, #elif, #else)")
+	fmt.Println("  ✓ 宏展开 (对象式宏、函数式宏、导出宏)")
+	fmt.Println("  ✓ #include 解析和包含关系图")
+	fmt.Println("  ✓ 类体外的成员函数定义与声明的关联")
 	fmt.Println()
 	fmt.Println("项目主页: https://github.com/yourusername/cpp-inheritance-analyzer")
 }
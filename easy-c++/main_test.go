@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"cpp-inheritance-analyzer/internal/analyzer"
+)
+
+// TestReports_ConflictingDefinitionCycle 测试同名类的冲突定义形成循环继承时报告能够生成
+func TestReports_ConflictingDefinitionCycle(t *testing.T) {
+	tempDir := t.TempDir()
+	projectDir := filepath.Join(tempDir, "project")
+	if err := os.Mkdir(projectDir, 0755); err != nil {
+		t.Fatalf("创建目录失败: %v", err)
+	}
+	files := map[string]string{
+		"a.h": "class A {};\nclass B : public A {};\n",
+		"b.h": "class B {};\nclass A : public B {};\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(projectDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+	}
+
+	a := analyzer.NewCppAnalyzer()
+	classes, err := a.AnalyzeProject(projectDir)
+	if err != nil {
+		t.Fatalf("项目分析失败: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		if err := generateTextReport(classes, a.IncludeGraph(), filepath.Join(tempDir, "report.txt")); err != nil {
+			done <- err
+			return
+		}
+		done <- generateHTMLReport(classes, a.IncludeGraph(), filepath.Join(tempDir, "report.html"))
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("生成报告失败: %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("生成报告超时，层次结构可能陷入循环")
+	}
+
+	for _, name := range []string{"report.txt", "report.html"} {
+		content, err := os.ReadFile(filepath.Join(tempDir, name))
+		if err != nil {
+			t.Fatalf("读取报告失败: %v", err)
+		}
+		if n := strings.Count(string(content), cycleMarker); n != 2 {
+			t.Errorf("%s: 期望 2 处循环继承标注，实际: %d", name, n)
+		}
+	}
+}
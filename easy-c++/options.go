@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cpp-inheritance-analyzer/internal/analyzer"
+)
+
+// macroOption 命令行上的一个 -D 或 -U 选项
+type macroOption struct {
+	Name     string // 宏名
+	Value    string // 宏的值，-D NAME 时为 1
+	Undefine bool   // 是否为 -U
+}
+
+// 外部基类在报告中的展示方式
+const (
+	externalShow     = "show"     // 作为独立的外部类节点展示，继承自它们的类挂在节点下
+	externalCollapse = "collapse" // 不单独展示节点，只在派生类的基类列表中标注
+	externalHide     = "hide"     // 从报告中隐藏外部基类
+)
+
+// 诊断的输出格式
+const (
+	diagnosticsText = "text" // 编译器风格，如 shape.h:3:7: warning: ...
+	diagnosticsJSON = "json" // JSON 数组，便于其他工具处理
+)
+
+// cliOptions 命令行中与位置参数无关的选项
+type cliOptions struct {
+	Macros       []macroOption // -D/-U 选项，按出现顺序生效
+	IncludePaths []string      // -I 指定的头文件搜索路径
+	External     string        // -external 指定的外部基类展示方式
+	Catalogs     []string      // -catalog 指定的内置目录名或目录文件
+	Diagnostics  string        // -diagnostics 指定的诊断输出格式
+	FailFast     bool          // --fail-fast: 遇到分析失败的文件时立即停止，默认 --keep-going 继续分析其余文件
+	Jobs         int           // -j 指定的并发解析文件数，0 表示使用 CPU 核数
+	CacheDir     string        // 缓存解析结果的目录，为空时不使用缓存
+	Includes     []string      // --include 指定的分析项目时作为起点的文件
+	Excludes     []string      // --exclude 指定的分析项目时排除的文件和目录
+	Gitignore    bool          // --gitignore: 分析项目时遵循 .gitignore
+	Extensions   []string      // -ext 指定的扩展名及其文件种类，如 .inc=header
+}
+
+// parseOptions 从命令行参数中取出选项，返回选项和剩余的位置参数
+// 支持 -D NAME[=VALUE]、-DNAME[=VALUE]、-U NAME、-UNAME、-I DIR 和 -IDIR 几种写法，NAME 可以带形参，如 -D "DECLARE(x)="
+// 以及 -external MODE、-catalog NAME|FILE、-ext EXT=KIND 和 -diagnostics FORMAT，后几个也可以写作 -external=MODE 的形式；
+// --include GLOB 和 --exclude GLOB 可以重复出现，也可以写作 --include=GLOB；-j N 也可以写作 -jN 或 -j=N；--fail-fast 和 --keep-going、--cache、-cache-dir DIR 和 --no-cache 以最后出现的为准
+func parseOptions(args []string) (cliOptions, []string, error) {
+	opts := cliOptions{External: externalShow, Diagnostics: diagnosticsText}
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch arg {
+		case "--fail-fast", "-fail-fast":
+			opts.FailFast = true
+			continue
+		case "--keep-going", "-keep-going":
+			opts.FailFast = false
+			continue
+		case "--cache", "-cache":
+			opts.CacheDir = analyzer.DefaultCacheDir
+			continue
+		case "--no-cache", "-no-cache":
+			opts.CacheDir = ""
+			continue
+		case "--gitignore", "-gitignore":
+			opts.Gitignore = true
+			continue
+		}
+		if value, ok, err := globOption(args, &i, "include"); err != nil {
+			return opts, nil, err
+		} else if ok {
+			opts.Includes = append(opts.Includes, value)
+			continue
+		}
+		if value, ok, err := globOption(args, &i, "exclude"); err != nil {
+			return opts, nil, err
+		} else if ok {
+			opts.Excludes = append(opts.Excludes, value)
+			continue
+		}
+		if value, ok, err := namedOption(args, &i, "-external"); err != nil {
+			return opts, nil, err
+		} else if ok {
+			if value != externalShow && value != externalCollapse && value != externalHide {
+				return opts, nil, fmt.Errorf("不支持的外部基类展示方式: %s (可选: show, collapse, hide)", value)
+			}
+			opts.External = value
+			continue
+		}
+		if value, ok, err := namedOption(args, &i, "-catalog"); err != nil {
+			return opts, nil, err
+		} else if ok {
+			opts.Catalogs = append(opts.Catalogs, value)
+			continue
+		}
+		if value, ok, err := namedOption(args, &i, "-ext"); err != nil {
+			return opts, nil, err
+		} else if ok {
+			if !strings.Contains(value, "=") {
+				return opts, nil, fmt.Errorf("选项 -ext 的格式应为 .ext=header|source|module|none: %s", value)
+			}
+			opts.Extensions = append(opts.Extensions, value)
+			continue
+		}
+		if value, ok, err := namedOption(args, &i, "-cache-dir"); err != nil {
+			return opts, nil, err
+		} else if ok {
+			opts.CacheDir = value
+			continue
+		}
+		if value, ok, err := jobsOption(args, &i); err != nil {
+			return opts, nil, err
+		} else if ok {
+			jobs, err := strconv.Atoi(value)
+			if err != nil || jobs < 1 {
+				return opts, nil, fmt.Errorf("无效的并发数: %s (应为正整数)", value)
+			}
+			opts.Jobs = jobs
+			continue
+		}
+		if value, ok, err := namedOption(args, &i, "-diagnostics"); err != nil {
+			return opts, nil, err
+		} else if ok {
+			if value != diagnosticsText && value != diagnosticsJSON {
+				return opts, nil, fmt.Errorf("不支持的诊断输出格式: %s (可选: text, json)", value)
+			}
+			opts.Diagnostics = value
+			continue
+		}
+		if !strings.HasPrefix(arg, "-D") && !strings.HasPrefix(arg, "-U") && !strings.HasPrefix(arg, "-I") {
+			rest = append(rest, arg)
+			continue
+		}
+
+		flag, value := arg[:2], arg[2:]
+		if value == "" {
+			if i+1 >= len(args) {
+				return opts, nil, fmt.Errorf("选项 %s 缺少参数", flag)
+			}
+			i++
+			value = args[i]
+		}
+		if flag == "-I" {
+			opts.IncludePaths = append(opts.IncludePaths, value)
+			continue
+		}
+		if flag == "-U" {
+			opts.Macros = append(opts.Macros, macroOption{Name: value, Undefine: true})
+			continue
+		}
+		name, val, found := strings.Cut(value, "=")
+		if !found {
+			// 与编译器一致，-D NAME 定义为 1，-D NAME= 定义为空
+			val = "1"
+		}
+		opts.Macros = append(opts.Macros, macroOption{Name: name, Value: val})
+	}
+	return opts, rest, nil
+}
+
+// namedOption 读取 args[*i] 处形如 -name VALUE 或 -name=VALUE 的选项，ok 表示 args[*i] 是该选项
+func namedOption(args []string, i *int, name string) (value string, ok bool, err error) {
+	arg := args[*i]
+	if arg != name && !strings.HasPrefix(arg, name+"=") {
+		return "", false, nil
+	}
+	if value, found := strings.CutPrefix(arg, name+"="); found {
+		return value, true, nil
+	}
+	if *i+1 >= len(args) {
+		return "", true, fmt.Errorf("选项 %s 缺少参数", name)
+	}
+	*i++
+	return args[*i], true, nil
+}
+
+// globOption 读取 args[*i] 处的 --name GLOB 或 -name GLOB 选项
+func globOption(args []string, i *int, name string) (value string, ok bool, err error) {
+	if value, ok, err := namedOption(args, i, "--"+name); ok {
+		return value, ok, err
+	}
+	return namedOption(args, i, "-"+name)
+}
+
+// jobsOption 读取 args[*i] 处的 -j N、-jN 或 -j=N 选项，ok 表示 args[*i] 是该选项
+func jobsOption(args []string, i *int) (value string, ok bool, err error) {
+	arg := args[*i]
+	if value, found := strings.CutPrefix(arg, "-j"); found && value != "" {
+		return strings.TrimPrefix(value, "="), true, nil
+	}
+	return namedOption(args, i, "-j")
+}
+
+// apply 将选项应用到分析器
+func (o cliOptions) apply(a *analyzer.CppAnalyzer) error {
+	a.SetFailFast(o.FailFast)
+	a.SetJobs(o.Jobs)
+	a.SetCacheDir(o.CacheDir)
+	a.SetGitignore(o.Gitignore)
+	for _, extension := range o.Extensions {
+		ext, kind, _ := strings.Cut(extension, "=")
+		if kind == "none" {
+			kind = ""
+		}
+		if err := a.SetExtension(ext, analyzer.FileKind(kind)); err != nil {
+			return err
+		}
+	}
+	for _, pattern := range o.Includes {
+		if err := a.IncludeFiles(pattern); err != nil {
+			return err
+		}
+	}
+	for _, pattern := range o.Excludes {
+		if err := a.ExcludeFiles(pattern); err != nil {
+			return err
+		}
+	}
+	for _, dir := range o.IncludePaths {
+		a.AddIncludePath(dir)
+	}
+	for _, macro := range o.Macros {
+		if macro.Undefine {
+			a.Undefine(macro.Name)
+		} else {
+			a.Define(macro.Name, macro.Value)
+		}
+	}
+	for _, catalog := range o.Catalogs {
+		if err := a.UseCatalog(catalog); err != nil {
+			return err
+		}
+	}
+	return nil
+}